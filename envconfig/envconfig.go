@@ -125,7 +125,7 @@ func gatherInfo(prefix string, spec interface{}) ([]varInfo, error) {
 
 		if f.Kind() == reflect.Struct {
 			// honor Decode if present
-			if decoderFrom(f) == nil && setterFrom(f) == nil && textUnmarshaler(f) == nil {
+			if decoderFrom(f) == nil && setterFrom(f) == nil && textUnmarshaler(f) == nil && optionalFrom(f) == nil {
 				innerPrefix := prefix
 				if !ftype.Anonymous {
 					innerPrefix = info.Key
@@ -165,6 +165,17 @@ func Process(prefix string, spec interface{}) error {
 			value = def
 		}
 
+		if ok && value == "" && isTrue(info.Tags.Get("notEmpty")) {
+			return fmt.Errorf("key %s must not be empty", info.Key)
+		}
+
+		// Optional fields record whether the variable was present, regardless of any default,
+		// and drop any value left over from an earlier Process
+		if opt := optionalFrom(info.Field); opt != nil {
+			opt.setPresence(ok, ok && value == "")
+			resetField(opt.valueField())
+		}
+
 		req := info.Tags.Get("required")
 		if !ok && def == "" {
 			if isTrue(req) {
@@ -284,9 +295,22 @@ func MustProcess(prefix string, spec interface{}) {
 	}
 }
 
+func resetField(field reflect.Value) {
+	field.Set(reflect.Zero(field.Type()))
+}
+
 func processField(value string, field reflect.Value) error {
 	typ := field.Type()
 
+	// an empty Optional is reset to its zero value rather than failing to parse
+	if opt := optionalFrom(field); opt != nil {
+		if value == "" {
+			resetField(opt.valueField())
+			return nil
+		}
+		return processField(value, opt.valueField())
+	}
+
 	decoder := decoderFrom(field)
 	if decoder != nil {
 		return decoder.Decode(value)
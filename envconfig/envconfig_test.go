@@ -715,3 +715,109 @@ func (ss *setterStruct) Set(value string) error {
 	ss.Inner = fmt.Sprintf("setterstruct{%q}", value)
 	return nil
 }
+
+func TestOptionalFields(t *testing.T) {
+	var s struct {
+		Unset         Optional[string]
+		Empty         Optional[string]
+		Present       Optional[int]
+		EmptyInt      Optional[int]
+		WithDefault   Optional[string] `default:"fallback"`
+		PointerToOpts *Optional[bool]
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_EMPTY", "")
+	os.Setenv("ENV_CONFIG_PRESENT", "42")
+	os.Setenv("ENV_CONFIG_EMPTYINT", "")
+	os.Setenv("ENV_CONFIG_POINTERTOOPTS", "true")
+
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if s.Unset.IsSet() || s.Unset.IsEmpty() {
+		t.Errorf("expected Unset to be unset, got set=%v empty=%v", s.Unset.IsSet(), s.Unset.IsEmpty())
+	}
+	if !s.Empty.IsSet() || !s.Empty.IsEmpty() {
+		t.Errorf("expected Empty to be set and empty, got set=%v empty=%v", s.Empty.IsSet(), s.Empty.IsEmpty())
+	}
+	if v, ok := s.Present.Get(); !ok || v != 42 {
+		t.Errorf("expected (42, true), got (%v, %v)", v, ok)
+	}
+	if !s.EmptyInt.IsEmpty() || s.EmptyInt.OrElse(7) != 7 {
+		t.Errorf("expected EmptyInt to be empty and fall back to 7, got %v", s.EmptyInt.OrElse(7))
+	}
+	if s.WithDefault.IsSet() {
+		t.Error("expected WithDefault to be unset")
+	}
+	if s.WithDefault.Value() != "fallback" {
+		t.Errorf("expected %q, got %q", "fallback", s.WithDefault.Value())
+	}
+	if s.PointerToOpts == nil || !s.PointerToOpts.Value() {
+		t.Errorf("expected PointerToOpts to be true, got %v", s.PointerToOpts)
+	}
+}
+
+func TestOptionalReprocess(t *testing.T) {
+	var s struct {
+		Empty       Optional[int]
+		Unset       Optional[int]
+		WithDefault Optional[int] `default:"3"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_EMPTY", "5")
+	os.Setenv("ENV_CONFIG_UNSET", "5")
+	os.Setenv("ENV_CONFIG_WITHDEFAULT", "5")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_EMPTY", "")
+	if err := Process("env_config", &s); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if s.Empty.Value() != 0 {
+		t.Errorf("expected Empty to be reset to 0, got %v", s.Empty.Value())
+	}
+	if s.Unset.IsSet() || s.Unset.Value() != 0 {
+		t.Errorf("expected Unset to be unset and reset to 0, got set=%v value=%v", s.Unset.IsSet(), s.Unset.Value())
+	}
+	if s.WithDefault.Value() != 3 {
+		t.Errorf("expected WithDefault to fall back to 3, got %v", s.WithDefault.Value())
+	}
+}
+
+func TestNotEmpty(t *testing.T) {
+	var s struct {
+		Name   string           `notEmpty:"true"`
+		Region Optional[string] `notEmpty:"true"`
+	}
+	os.Clearenv()
+	if err := Process("env_config", &s); err != nil {
+		t.Errorf("expected unset notEmpty keys to be accepted, got %s", err)
+	}
+
+	os.Setenv("ENV_CONFIG_NAME", "")
+	if err := Process("env_config", &s); err == nil {
+		t.Error("expected error for empty ENV_CONFIG_NAME, got none")
+	}
+
+	os.Setenv("ENV_CONFIG_NAME", "foo")
+	os.Setenv("ENV_CONFIG_REGION", "")
+	if err := Process("env_config", &s); err == nil {
+		t.Error("expected error for empty ENV_CONFIG_REGION, got none")
+	}
+}
+
+func TestRequiredNotEmpty(t *testing.T) {
+	var s struct {
+		Token string `required:"true" notEmpty:"true"`
+	}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_TOKEN", "")
+	if err := Process("env_config", &s); err == nil {
+		t.Error("expected error for empty required ENV_CONFIG_TOKEN, got none")
+	}
+}
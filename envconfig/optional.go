@@ -0,0 +1,65 @@
+package envconfig
+
+import "reflect"
+
+// Optional wraps a configuration value and records whether its environment
+// variable was present. Unlike a pointer field, it lets callers distinguish
+// an unset variable from one that was explicitly set to an empty string.
+//
+// A default tag still populates the value of an unset Optional, but IsSet
+// keeps reporting false so the caller can tell the default was used.
+type Optional[T any] struct {
+	value T
+	set   bool
+	empty bool
+}
+
+// Get returns the value and whether its environment variable was present
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// Value returns the value, which is the zero value or the default tag when the variable is unset or empty
+func (o Optional[T]) Value() T {
+	return o.value
+}
+
+// IsSet reports whether the environment variable was present, even if empty
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsEmpty reports whether the environment variable was present but set to an empty string
+func (o Optional[T]) IsEmpty() bool {
+	return o.set && o.empty
+}
+
+// OrElse returns the value if the environment variable was present and non-empty, otherwise def
+func (o Optional[T]) OrElse(def T) T {
+	if !o.set || o.empty {
+		return def
+	}
+	return o.value
+}
+
+func (o *Optional[T]) valueField() reflect.Value {
+	return reflect.ValueOf(&o.value).Elem()
+}
+
+func (o *Optional[T]) setPresence(set bool, empty bool) {
+	o.set = set
+	o.empty = empty
+}
+
+// optional is implemented by every instantiation of *Optional
+type optional interface {
+	valueField() reflect.Value
+	setPresence(set bool, empty bool)
+}
+
+var optionalType = reflect.TypeOf((*optional)(nil)).Elem()
+
+func optionalFrom(field reflect.Value) (o optional) {
+	interfaceFrom(field, func(v interface{}, ok *bool) { o, *ok = v.(optional) })
+	return o
+}
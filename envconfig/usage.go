@@ -66,6 +66,9 @@ func toTypeDescription(t reflect.Type) string {
 	case reflect.Ptr:
 		return toTypeDescription(t.Elem())
 	case reflect.Struct:
+		if reflect.PtrTo(t).Implements(optionalType) {
+			return toTypeDescription(reflect.New(t).Interface().(optional).valueField().Type())
+		}
 		if implementsInterface(t) && t.Name() != "" {
 			return t.Name()
 		}
@@ -0,0 +1,261 @@
+package jsonhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Client calls JSON APIs that respond with an APIResponse, like the services built with this package. The zero value is ready to use
+type Client struct {
+	// HTTPClient sends the requests. Defaults to http.DefaultClient
+	HTTPClient *http.Client
+	// BaseURL is prepended to the path of every request, e.g. http://users.internal/api
+	BaseURL string
+	// Header is added to every request, e.g. for authorization
+	Header http.Header
+	// MaxRetries is the number of times idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE) are retried after a transport error or a 429, 502, 503 or 504 response
+	MaxRetries int
+	// Backoff returns the delay before the provided retry, starting at 1. A Retry-After header takes precedence. Defaults to exponential backoff with jitter from 100ms up to 5s
+	Backoff func(retry int) time.Duration
+}
+
+// ResponseError is returned by Client for APIResponses with success false or an error status code. Problem details responses are converted, with the detail (or title) as the message
+type ResponseError struct {
+	StatusCode int
+	Message    string
+	Debug      string
+	Data       json.RawMessage
+	// Err is the error decoding the body of error responses that are not APIResponses, like the HTML pages of proxies and load balancers
+	Err error
+}
+
+// Error returns the status code, message and debug detail of the response
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("jsonhttp: %d %s", e.StatusCode, e.Message)
+	if e.Debug != "" {
+		msg += ": " + e.Debug
+	}
+	return msg
+}
+
+// Unwrap returns Err, so errors.Is and errors.As can inspect why the response could not be decoded
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// Get sends a GET request and decodes the data of the response into out
+func (c *Client) Get(ctx context.Context, path string, out interface{}) error {
+	return c.Do(ctx, http.MethodGet, path, nil, out)
+}
+
+// Post sends body as JSON in a POST request and decodes the data of the response into out
+func (c *Client) Post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	return c.Do(ctx, http.MethodPost, path, body, out)
+}
+
+// Put sends body as JSON in a PUT request and decodes the data of the response into out
+func (c *Client) Put(ctx context.Context, path string, body interface{}, out interface{}) error {
+	return c.Do(ctx, http.MethodPut, path, body, out)
+}
+
+// Patch sends body as JSON in a PATCH request and decodes the data of the response into out
+func (c *Client) Patch(ctx context.Context, path string, body interface{}, out interface{}) error {
+	return c.Do(ctx, http.MethodPatch, path, body, out)
+}
+
+// Delete sends a DELETE request and decodes the data of the response into out
+func (c *Client) Delete(ctx context.Context, path string, out interface{}) error {
+	return c.Do(ctx, http.MethodDelete, path, nil, out)
+}
+
+// Do sends a request with body encoded as JSON and decodes the data of the APIResponse into out, either of which can be nil. Unsuccessful responses are returned as a *ResponseError
+func (c *Client) Do(ctx context.Context, method string, path string, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("jsonhttp: marshal request: %w", err)
+		}
+	}
+
+	for retry := 0; ; retry++ {
+		resp, err := c.send(ctx, method, path, payload)
+		if retry >= c.MaxRetries || !isIdempotent(method) || !shouldRetry(resp, err) {
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			return decodeResponse(resp, out)
+		}
+
+		delay := c.backoff(retry + 1)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				delay = after
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			// Keep the transport error of the last attempt, if any, while still matching the context error
+			return errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// send sends a single attempt of a request
+func (c *Client) send(ctx context.Context, method string, path string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json, "+ProblemContentType)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return httpClient.Do(req)
+}
+
+// backoff returns the delay before the provided retry
+func (c *Client) backoff(retry int) time.Duration {
+	if c.Backoff != nil {
+		return c.Backoff(retry)
+	}
+	delay := min(100*time.Millisecond<<min(retry-1, 10), 5*time.Second)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether an attempt failed in a way that a later attempt may not. Errors of the request context are final
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the delay of the Retry-After header of the response
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// clientResponse is an APIResponse with the data left encoded
+type clientResponse struct {
+	Message string          `json:"message"`
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Debug   string          `json:"debug"`
+}
+
+// decodeResponse decodes the APIResponse of resp, returning a *ResponseError if it is not successful
+func decodeResponse(resp *http.Response, out interface{}) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	ok := resp.StatusCode >= 200 && resp.StatusCode < 300
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == ProblemContentType {
+		var p Problem
+		if err := json.Unmarshal(body, &p); err == nil {
+			return problemError(resp.StatusCode, p)
+		}
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if ok {
+			return nil
+		}
+		return &ResponseError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	}
+
+	var apiResp clientResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		if !ok {
+			// Error responses of proxies and load balancers are usually not JSON
+			return &ResponseError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode), Err: err}
+		}
+		return fmt.Errorf("jsonhttp: decode response: %w", err)
+	}
+	if !ok || !apiResp.Success {
+		return &ResponseError{
+			StatusCode: resp.StatusCode,
+			Message:    apiResp.Message,
+			Debug:      apiResp.Debug,
+			Data:       apiResp.Data,
+		}
+	}
+
+	if out == nil || len(apiResp.Data) == 0 || bytes.Equal(apiResp.Data, []byte("null")) {
+		return nil
+	}
+	if err := json.Unmarshal(apiResp.Data, out); err != nil {
+		return fmt.Errorf("jsonhttp: decode response data: %w", err)
+	}
+	return nil
+}
+
+// problemError converts problem details into a *ResponseError, reversing problemFromResponse
+func problemError(statusCode int, p Problem) *ResponseError {
+	e := &ResponseError{StatusCode: statusCode, Message: p.Detail}
+	if e.Message == "" {
+		e.Message = p.Title
+	}
+	if debug, ok := p.Extensions["debug"].(string); ok {
+		e.Debug = debug
+	}
+	if data, ok := p.Extensions["data"]; ok {
+		e.Data, _ = json.Marshal(data)
+	}
+	return e
+}
@@ -0,0 +1,230 @@
+package jsonhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// noBackoff retries immediately
+func noBackoff(retry int) time.Duration {
+	return 0
+}
+
+func TestClientDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("Accept") != "application/json, "+ProblemContentType {
+			JSONForbiddenError(w, "", "")
+			return
+		}
+		var in map[string]string
+		if r.Method == http.MethodPost {
+			if r.Header.Get("Content-Type") != "application/json" {
+				JSONBadRequestError(w, "", "")
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&in)
+		}
+		JSONSuccess(w, map[string]string{"path": r.URL.Path, "name": in["name"]}, "")
+	}))
+	defer server.Close()
+
+	c := &Client{BaseURL: server.URL + "/api", Header: http.Header{"Authorization": {"Bearer token"}}}
+	var out struct {
+		Path string `json:"path"`
+		Name string `json:"name"`
+	}
+	if err := c.Post(context.Background(), "/users", map[string]string{"name": "alice"}, &out); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if out.Path != "/api/users" || out.Name != "alice" {
+		t.Errorf("Response data should be decoded, got %+v", out)
+	}
+}
+
+func TestClientResponseErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		status      int
+		message     string
+		debug       string
+		data        string
+		decodeError bool
+	}{
+		{"APIResponse", func(w http.ResponseWriter, r *http.Request) {
+			JSONNotFoundError(w, "no_user", "user 42")
+		}, http.StatusNotFound, "no_user", "user 42", "null", false},
+		{"success false", func(w http.ResponseWriter, r *http.Request) {
+			JSONDetailed(w, APIResponse{Message: "quota_exceeded", Data: []int{1}}, http.StatusOK)
+		}, http.StatusOK, "quota_exceeded", "", "[1]", false},
+		{"problem details", ProblemDetails(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			JSONError(w, []string{"name"}, "validation_failed", "details", http.StatusUnprocessableEntity)
+		})), http.StatusUnprocessableEntity, "validation_failed", "details", `["name"]`, false},
+		{"problem title", func(w http.ResponseWriter, r *http.Request) {
+			JSONProblem(w, Problem{Status: http.StatusConflict, Title: "Conflict"})
+		}, http.StatusConflict, "Conflict", "", "", false},
+		{"proxy HTML", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			io.WriteString(w, "<html><body>502 Bad Gateway</body></html>")
+		}, http.StatusBadGateway, "Bad Gateway", "", "", true},
+		{"empty body", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}, http.StatusUnauthorized, "Unauthorized", "", "", false},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(tt.handler)
+		err := (&Client{BaseURL: server.URL}).Get(context.Background(), "/", nil)
+		server.Close()
+
+		var respErr *ResponseError
+		if !errors.As(err, &respErr) {
+			t.Errorf("%s: error should be a *ResponseError, got %v", tt.name, err)
+			continue
+		}
+		if respErr.StatusCode != tt.status || respErr.Message != tt.message || respErr.Debug != tt.debug || string(respErr.Data) != tt.data {
+			t.Errorf("%s: error should be %d %s %s %s, got %d %s %s %s", tt.name, tt.status, tt.message, tt.debug, tt.data, respErr.StatusCode, respErr.Message, respErr.Debug, respErr.Data)
+		}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) != tt.decodeError {
+			t.Errorf("%s: the decode error should be unwrapped: %v, got %v", tt.name, tt.decodeError, respErr.Err)
+		}
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		failures int32
+		status   int
+		attempts int32
+		ok       bool
+	}{
+		{"recovers", http.MethodGet, 2, http.StatusServiceUnavailable, 3, true},
+		{"gives up", http.MethodGet, 5, http.StatusServiceUnavailable, 4, false},
+		{"PUT is idempotent", http.MethodPut, 1, http.StatusBadGateway, 2, true},
+		{"POST is not retried", http.MethodPost, 1, http.StatusServiceUnavailable, 1, false},
+		{"PATCH is not retried", http.MethodPatch, 1, http.StatusServiceUnavailable, 1, false},
+		{"client errors are final", http.MethodGet, 1, http.StatusNotFound, 1, false},
+	}
+
+	for _, tt := range tests {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) <= tt.failures {
+				JSONError(w, nil, "", "", tt.status)
+				return
+			}
+			JSONSuccess(w, nil, "")
+		}))
+		c := &Client{BaseURL: server.URL, MaxRetries: 3, Backoff: noBackoff}
+		err := c.Do(context.Background(), tt.method, "/", map[string]int{}, nil)
+		server.Close()
+
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if attempts.Load() != tt.attempts {
+			t.Errorf("%s: %d attempts should be made, got %d", tt.name, tt.attempts, attempts.Load())
+		}
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			JSONError(w, nil, "", "", http.StatusTooManyRequests)
+			return
+		}
+		JSONSuccess(w, nil, "")
+	}))
+	defer server.Close()
+
+	// Retry-After takes precedence over the backoff
+	c := &Client{BaseURL: server.URL, MaxRetries: 1, Backoff: func(retry int) time.Duration { return time.Hour }}
+	start := time.Now()
+	if err := c.Get(context.Background(), "/", nil); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 30*time.Second {
+		t.Errorf("The retry should wait for Retry-After, waited %s", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		delay  time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set("Retry-After", tt.header)
+		if delay, ok := retryAfter(resp); delay != tt.delay || ok != tt.ok {
+			t.Errorf("Retry-After %q should be %s %v, got %s %v", tt.header, tt.delay, tt.ok, delay, ok)
+		}
+	}
+}
+
+func TestClientCancelDuringBackoff(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		JSONError(w, nil, "", "", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	c := &Client{BaseURL: server.URL, MaxRetries: 3, Backoff: func(retry int) time.Duration { return time.Hour }}
+
+	start := time.Now()
+	err := c.Get(ctx, "/", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Error should be the context error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("The backoff should stop when the context is done, waited %s", elapsed)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("No retry should be made after the context is done, got %d attempts", attempts.Load())
+	}
+}
+
+func TestClientCancelAfterTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{BaseURL: url, MaxRetries: 3, Backoff: func(retry int) time.Duration {
+		cancel()
+		return time.Hour
+	}}
+	err := c.Get(ctx, "/", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Error should match the context error, got %v", err)
+	}
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		t.Errorf("Transport errors should not be a *ResponseError, got %v", err)
+	}
+}
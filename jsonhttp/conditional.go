@@ -0,0 +1,154 @@
+package jsonhttp
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Validators are the cache validators of a resource, used by JSONSuccessConditional and CheckPreconditions
+type Validators struct {
+	// ETag is the entity tag of the resource, see StrongETag and WeakETag. JSONSuccessConditional computes it from the encoded response when empty
+	ETag string
+	// Weak makes the ETag computed by JSONSuccessConditional weak
+	Weak bool
+	// LastModified is the modification time of the resource. The zero value is not sent
+	LastModified time.Time
+}
+
+// StrongETag returns a strong entity tag for the version of a resource, e.g. a revision number or hash
+func StrongETag(version string) string {
+	return `"` + version + `"`
+}
+
+// WeakETag returns a weak entity tag for the version of a resource, for versions that only change when the resource changes semantically
+func WeakETag(version string) string {
+	return `W/"` + version + `"`
+}
+
+// JSONSuccessConditional returns a successful APIResponse on the http response like JSONSuccess, with ETag and Last-Modified headers, or 304 Not Modified if the conditional headers show the client already has it
+func JSONSuccessConditional(w http.ResponseWriter, r *http.Request, data interface{}, message string, v Validators) {
+	if message == "" {
+		message = "ok"
+	}
+	resp := APIResponse{
+		Message: message,
+		Success: true,
+		Data:    data,
+	}
+	dj, ok := marshalJSON(w, resp)
+	if !ok {
+		return
+	}
+
+	if v.ETag == "" {
+		sum := sha256.Sum256(dj)
+		v.ETag = StrongETag(base64.RawURLEncoding.EncodeToString(sum[:16]))
+		if v.Weak {
+			v.ETag = "W/" + v.ETag
+		}
+	}
+	w.Header().Set("ETag", v.ETag)
+	if !v.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", v.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if isSafeMethod(r.Method) && notModified(r, v) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if !isSafeMethod(r.Method) && matchesETag(r.Header.Get("If-None-Match"), v.ETag, false) {
+		JSONError(w, nil, "precondition_failed", "", http.StatusPreconditionFailed)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeFor(w))
+	w.WriteHeader(http.StatusOK)
+	w.Write(dj)
+}
+
+// CheckPreconditions evaluates the conditional headers of a request that changes the resource with the provided validators. If a precondition fails, 412 Precondition Failed is sent and false is returned
+func CheckPreconditions(w http.ResponseWriter, r *http.Request, v Validators) bool {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if !matchesETag(ifMatch, v.ETag, true) {
+			JSONError(w, nil, "precondition_failed", "", http.StatusPreconditionFailed)
+			return false
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil && !v.LastModified.IsZero() {
+		if v.LastModified.Truncate(time.Second).After(since) {
+			JSONError(w, nil, "precondition_failed", "", http.StatusPreconditionFailed)
+			return false
+		}
+	}
+	if matchesETag(r.Header.Get("If-None-Match"), v.ETag, false) {
+		JSONError(w, nil, "precondition_failed", "", http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+// notModified reports whether the conditional headers of a GET or HEAD request match the validators. If-Modified-Since is ignored when If-None-Match is present
+func notModified(r *http.Request, v Validators) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return matchesETag(ifNoneMatch, v.ETag, false)
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || v.LastModified.IsZero() {
+		return false
+	}
+	return !v.LastModified.Truncate(time.Second).After(since)
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// matchesETag reports whether the list of entity tags of a conditional header matches etag. "*" matches any existing resource. Strong comparison never matches weak entity tags, as required for If-Match
+func matchesETag(header string, etag string, strong bool) bool {
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range parseETags(header) {
+		if candidate == "*" {
+			return true
+		}
+		if strong && (strings.HasPrefix(candidate, "W/") || strings.HasPrefix(etag, "W/")) {
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseETags returns the entity tags of a comma-separated If-Match or If-None-Match header, stopping at the first invalid one
+func parseETags(header string) []string {
+	var etags []string
+	for {
+		header = strings.TrimLeft(header, " \t,")
+		if header == "" {
+			return etags
+		}
+		if header[0] == '*' {
+			etags = append(etags, "*")
+			header = header[1:]
+			continue
+		}
+		prefix := ""
+		if strings.HasPrefix(header, "W/") {
+			prefix, header = "W/", header[2:]
+		}
+		if header == "" || header[0] != '"' {
+			return etags
+		}
+		end := strings.IndexByte(header[1:], '"')
+		if end < 0 {
+			return etags
+		}
+		etags = append(etags, prefix+header[:end+2])
+		header = header[end+2:]
+	}
+}
@@ -0,0 +1,162 @@
+package jsonhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseETags(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{``, nil},
+		{`*`, []string{"*"}},
+		{`"a", W/"b",,"c,d"`, []string{`"a"`, `W/"b"`, `"c,d"`}},
+		{`"a", b, "c"`, []string{`"a"`}},
+		{`"a", W/`, []string{`"a"`}},
+		{`"unterminated`, nil},
+	}
+
+	for _, tt := range tests {
+		if got := parseETags(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Entity tags of %q should be %q, got %q", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestMatchesETag(t *testing.T) {
+	tests := []struct {
+		header string
+		etag   string
+		strong bool
+		want   bool
+	}{
+		{`"a"`, `"a"`, false, true},
+		{`"a"`, `"a"`, true, true},
+		{`W/"a"`, `"a"`, false, true},
+		{`W/"a"`, `"a"`, true, false},
+		{`"a"`, `W/"a"`, true, false},
+		{`"b", "a"`, `"a"`, true, true},
+		{`"b"`, `"a"`, false, false},
+		{`*`, `"a"`, true, true},
+		{`*`, ``, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesETag(tt.header, tt.etag, tt.strong); got != tt.want {
+			t.Errorf("%q should match %q (strong %v): %v, got %v", tt.header, tt.etag, tt.strong, tt.want, got)
+		}
+	}
+}
+
+func TestJSONSuccessConditional(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500_000_000, time.UTC)
+	v := Validators{ETag: `"v1"`, LastModified: modified}
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		status  int
+	}{
+		{"unconditional", "GET", nil, http.StatusOK},
+		{"If-None-Match", "GET", map[string]string{"If-None-Match": `"v0", "v1"`}, http.StatusNotModified},
+		{"weak If-None-Match", "HEAD", map[string]string{"If-None-Match": `W/"v1"`}, http.StatusNotModified},
+		{"changed If-None-Match", "GET", map[string]string{"If-None-Match": `"v0"`}, http.StatusOK},
+		{"If-None-Match wins", "GET", map[string]string{"If-None-Match": `"v0"`, "If-Modified-Since": modified.Format(http.TimeFormat)}, http.StatusOK},
+		// Last-Modified has second precision, so the sub-second part of the modification time is ignored
+		{"If-Modified-Since", "GET", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified since", "GET", map[string]string{"If-Modified-Since": modified.Add(-time.Second).Format(http.TimeFormat)}, http.StatusOK},
+		{"invalid If-Modified-Since", "GET", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+		{"unsafe method", "POST", map[string]string{"If-None-Match": "*"}, http.StatusPreconditionFailed},
+		{"unsafe method If-Modified-Since", "POST", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/", nil)
+		for k, val := range tt.headers {
+			r.Header.Set(k, val)
+		}
+		w := httptest.NewRecorder()
+		JSONSuccessConditional(w, r, "data", "", v)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status should be %d, got %d", tt.name, tt.status, w.Code)
+		}
+		if w.Header().Get("ETag") != `"v1"` || w.Header().Get("Last-Modified") != "Wed, 01 May 2024 12:00:00 GMT" {
+			t.Errorf("%s: validators should be sent, got %v", tt.name, w.Header())
+		}
+		if tt.status == http.StatusNotModified && w.Body.Len() != 0 {
+			t.Errorf("%s: 304 should have no body, got %s", tt.name, w.Body.String())
+		}
+	}
+}
+
+func TestJSONSuccessConditionalComputedETag(t *testing.T) {
+	var etags []string
+	for _, weak := range []bool{false, true} {
+		w := httptest.NewRecorder()
+		JSONSuccessConditional(w, httptest.NewRequest("GET", "/", nil), "data", "", Validators{Weak: weak})
+		etag := w.Header().Get("ETag")
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		JSONSuccessConditional(w, r, "data", "", Validators{Weak: weak})
+		if w.Code != http.StatusNotModified {
+			t.Errorf("The computed ETag %s should be stable, got %d", etag, w.Code)
+		}
+		etags = append(etags, etag)
+	}
+	if len(etags[0]) < 3 || etags[0][0] != '"' || etags[1] != "W/"+etags[0] {
+		t.Errorf("A strong and a weak ETag should be computed, got %v", etags)
+	}
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500_000_000, time.UTC)
+	existing := Validators{ETag: `"v1"`, LastModified: modified}
+	missing := Validators{}
+
+	tests := []struct {
+		name    string
+		v       Validators
+		headers map[string]string
+		ok      bool
+	}{
+		{"unconditional", existing, nil, true},
+		{"If-Match", existing, map[string]string{"If-Match": `"v1"`}, true},
+		{"stale If-Match", existing, map[string]string{"If-Match": `"v0"`}, false},
+		{"weak If-Match", existing, map[string]string{"If-Match": `W/"v1"`}, false},
+		{"If-Match *", existing, map[string]string{"If-Match": "*"}, true},
+		{"If-Match * missing", missing, map[string]string{"If-Match": "*"}, false},
+		{"If-None-Match * missing", missing, map[string]string{"If-None-Match": "*"}, true},
+		{"If-None-Match * existing", existing, map[string]string{"If-None-Match": "*"}, false},
+		{"If-Unmodified-Since", existing, map[string]string{"If-Unmodified-Since": modified.Format(http.TimeFormat)}, true},
+		{"modified since", existing, map[string]string{"If-Unmodified-Since": modified.Add(-time.Second).Format(http.TimeFormat)}, false},
+		{"If-Match wins", existing, map[string]string{"If-Match": `"v1"`, "If-Unmodified-Since": modified.Add(-time.Second).Format(http.TimeFormat)}, true},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("PUT", "/", nil)
+		for k, val := range tt.headers {
+			r.Header.Set(k, val)
+		}
+		w := httptest.NewRecorder()
+		ok := CheckPreconditions(w, r, tt.v)
+
+		if ok != tt.ok {
+			t.Errorf("%s: preconditions should pass: %v, got %v", tt.name, tt.ok, ok)
+		}
+		if !ok && w.Code != http.StatusPreconditionFailed {
+			t.Errorf("%s: failed preconditions should respond 412, got %d", tt.name, w.Code)
+		}
+		if ok && w.Body.Len() != 0 {
+			t.Errorf("%s: nothing should be sent when the preconditions pass, got %s", tt.name, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package jsonhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedMediaType is returned when DecodeOptions.RequireJSONContentType is set and the request is not application/json
+	ErrUnsupportedMediaType = errors.New("jsonhttp: request content type must be application/json")
+	// ErrTrailingData is returned when DecodeOptions.SingleValue is set and the request body has data after the first JSON value
+	ErrTrailingData = errors.New("jsonhttp: request body must contain a single JSON value")
+)
+
+// DecodeOptions hardens the decoding of request bodies. The zero value decodes like JSONDecodeNoCatch always has. Services configure the options once and call their methods, or pass them to HandleWith, instead of the package-level functions
+type DecodeOptions struct {
+	// MaxBytes limits the size of the request body with http.MaxBytesReader. Larger bodies are answered with 413 Request Entity Too Large. Zero means no limit
+	MaxBytes int64
+	// DisallowUnknownFields rejects objects with keys that do not match a field of the destination struct
+	DisallowUnknownFields bool
+	// SingleValue rejects bodies with anything but whitespace after the first JSON value
+	SingleValue bool
+	// RequireJSONContentType answers requests without an application/json (or +json) Content-Type with 415 Unsupported Media Type
+	RequireJSONContentType bool
+}
+
+// Decode decodes checkable (and non-checkable) payloads into structs like JSONDecodeNoCatch, applying the options
+func (o DecodeOptions) Decode(r *http.Request, outStruct interface{}) error {
+	err := o.decodeBody(nil, r, outStruct)
+	if err != nil {
+		return err
+	}
+	return checkParameters(outStruct)
+}
+
+// DecodeAndCatchForAPI is JSONDecodeAndCatchForAPI with the options applied, sending the matching error response when the request violates them
+func (o DecodeOptions) DecodeAndCatchForAPI(w http.ResponseWriter, r *http.Request, outStruct interface{}) error {
+	err := o.decodeBody(w, r, outStruct)
+	if err != nil {
+		JSONErrorFrom(w, decodeError(err))
+		return err
+	}
+	err = checkParameters(outStruct)
+	if err != nil {
+		JSONErrorFrom(w, parametersError(err))
+		return err
+	}
+	return nil
+}
+
+// decodeBody decodes the JSON request body into outStruct. w is only used to let http.MaxBytesReader close the connection and may be nil
+func (o DecodeOptions) decodeBody(w http.ResponseWriter, r *http.Request, outStruct interface{}) error {
+	if o.RequireJSONContentType && !isJSONContentType(r.Header.Get("Content-Type")) {
+		return ErrUnsupportedMediaType
+	}
+
+	body := r.Body
+	if o.MaxBytes > 0 {
+		body = http.MaxBytesReader(w, body, o.MaxBytes)
+	}
+
+	decoder := json.NewDecoder(body)
+	if o.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	err := decoder.Decode(&outStruct)
+	if err != nil {
+		return err
+	}
+
+	if o.SingleValue {
+		_, err := decoder.Token()
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return err
+		}
+		if err != io.EOF {
+			return ErrTrailingData
+		}
+	}
+	return nil
+}
+
+// isJSONContentType reports whether the Content-Type header is application/json or a structured +json media type
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// decodeError converts an error returned while decoding a request body into the APIError sent to the client
+func decodeError(err error) *APIError {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return NewAPIError(http.StatusRequestEntityTooLarge, "request_too_large", "").Wrap(err)
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return NewAPIError(http.StatusUnsupportedMediaType, "unsupported_media_type", err.Error()).Wrap(err)
+	case errors.Is(err, ErrTrailingData):
+		return BadRequestError("Invalid JSON", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidPatch):
+		return NewAPIError(http.StatusUnprocessableEntity, "invalid_patch", err.Error()).Wrap(err)
+	case errors.Is(err, ErrPatchTestFailed):
+		return NewAPIError(http.StatusConflict, "patch_test_failed", err.Error()).Wrap(err)
+	}
+	if fieldErrs := decodeFieldErrors(err); fieldErrs != nil {
+		return BadRequestError("Invalid JSON", err.Error()).WithData(fieldErrs).Wrap(err)
+	}
+	return BadRequestError("Invalid JSON", "").Wrap(err)
+}
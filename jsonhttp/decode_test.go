@@ -0,0 +1,98 @@
+package jsonhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type decodePayload struct {
+	Name string `json:"name"`
+}
+
+func newJSONRequest(body string, contentType string) *http.Request {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func TestDecodeOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        DecodeOptions
+		body        string
+		contentType string
+		status      int
+		message     string
+	}{
+		{"zero value", DecodeOptions{}, `{"name":"a","extra":1} trailing`, "", http.StatusOK, ""},
+		{"within MaxBytes", DecodeOptions{MaxBytes: 64}, `{"name":"a"}`, "", http.StatusOK, ""},
+		{"MaxBytes", DecodeOptions{MaxBytes: 8}, `{"name":"too long"}`, "", http.StatusRequestEntityTooLarge, "request_too_large"},
+		{"MaxBytes trailing data", DecodeOptions{MaxBytes: 16, SingleValue: true}, `{"name":"a"}    {"name":"b"}`, "", http.StatusRequestEntityTooLarge, "request_too_large"},
+		{"application/json", DecodeOptions{RequireJSONContentType: true}, `{"name":"a"}`, "application/json; charset=utf-8", http.StatusOK, ""},
+		{"+json", DecodeOptions{RequireJSONContentType: true}, `{"name":"a"}`, "application/vnd.api+json", http.StatusOK, ""},
+		{"text/plain", DecodeOptions{RequireJSONContentType: true}, `{"name":"a"}`, "text/plain", http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{"missing content type", DecodeOptions{RequireJSONContentType: true}, `{"name":"a"}`, "", http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{"DisallowUnknownFields", DecodeOptions{DisallowUnknownFields: true}, `{"name":"a","extra":1}`, "", http.StatusBadRequest, "Invalid JSON"},
+		{"SingleValue", DecodeOptions{SingleValue: true}, `{"name":"a"} {"name":"b"}`, "", http.StatusBadRequest, "Invalid JSON"},
+		{"SingleValue whitespace", DecodeOptions{SingleValue: true}, "{\"name\":\"a\"}\n\t ", "", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		var out decodePayload
+		w := httptest.NewRecorder()
+		err := tt.opts.DecodeAndCatchForAPI(w, newJSONRequest(tt.body, tt.contentType), &out)
+
+		if (err == nil) != (tt.status == http.StatusOK) {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if tt.status == http.StatusOK {
+			if out.Name != "a" {
+				t.Errorf("%s: name should be decoded, got %q", tt.name, out.Name)
+			}
+			continue
+		}
+		if w.Code != tt.status {
+			t.Errorf("%s: status should be %d, got %d", tt.name, tt.status, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `"message":"`+tt.message+`"`) {
+			t.Errorf("%s: message should be %s, got %s", tt.name, tt.message, w.Body.String())
+		}
+	}
+}
+
+func TestDecodeOptionsErrors(t *testing.T) {
+	var out decodePayload
+
+	err := DecodeOptions{RequireJSONContentType: true}.Decode(newJSONRequest(`{}`, "text/plain"), &out)
+	if !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Decode should return ErrUnsupportedMediaType, got %v", err)
+	}
+
+	err = DecodeOptions{SingleValue: true}.Decode(newJSONRequest(`{} []`, ""), &out)
+	if !errors.Is(err, ErrTrailingData) {
+		t.Errorf("Decode should return ErrTrailingData, got %v", err)
+	}
+
+	err = DecodeOptions{MaxBytes: 2}.Decode(newJSONRequest(`{"name":"a"}`, ""), &out)
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		t.Errorf("Decode should return *http.MaxBytesError, got %v", err)
+	}
+}
+
+func TestHandleWith(t *testing.T) {
+	h := HandleWith(DecodeOptions{DisallowUnknownFields: true}, func(ctx context.Context, req decodePayload) (string, error) {
+		return req.Name, nil
+	})
+
+	w := serveHandle(h, "POST", `{"name":"a","extra":1}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("HandleWith should apply the decode options, got %d %s", w.Code, w.Body.String())
+	}
+}
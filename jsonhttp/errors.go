@@ -0,0 +1,109 @@
+package jsonhttp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// APIError is an error that carries the HTTP response it should be rendered as. See JSONErrorFrom for the usage
+type APIError struct {
+	StatusCode int
+	Message    string
+	Debug      string
+	Data       interface{}
+	Err        error
+}
+
+// NewAPIError returns an APIError with the provided status code, public message and debug detail
+func NewAPIError(statusCode int, message string, debug string) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Message:    message,
+		Debug:      debug,
+	}
+}
+
+// BadRequestError returns a bad request APIError with the provided parameters
+func BadRequestError(message string, debug string) *APIError {
+	return NewAPIError(http.StatusBadRequest, message, debug)
+}
+
+// NotFoundError returns a not found APIError with the provided parameters
+func NotFoundError(message string, debug string) *APIError {
+	return NewAPIError(http.StatusNotFound, message, debug)
+}
+
+// ForbiddenError returns a forbidden APIError with the provided parameters
+func ForbiddenError(message string, debug string) *APIError {
+	return NewAPIError(http.StatusForbidden, message, debug)
+}
+
+// InternalError returns an internal server error APIError with the provided parameters
+func InternalError(message string, debug string) *APIError {
+	return NewAPIError(http.StatusInternalServerError, message, debug)
+}
+
+// WithData returns a copy of the APIError that also sends the provided data in the response
+func (e *APIError) WithData(data interface{}) *APIError {
+	c := *e
+	c.Data = data
+	return &c
+}
+
+// Wrap returns a copy of the APIError that wraps err, so the cause is available to errors.Is and errors.As without being sent to the client
+func (e *APIError) Wrap(err error) *APIError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// Error returns the message, debug detail and wrapped error of the APIError
+func (e *APIError) Error() string {
+	msg := e.message()
+	if e.Debug != "" {
+		msg += ": " + e.Debug
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the wrapped error
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// status returns the status code of the APIError, defaulting to 500
+func (e *APIError) status() int {
+	if e.StatusCode == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.StatusCode
+}
+
+// message returns the public message of the APIError, defaulting to the message of the matching JSONError function
+func (e *APIError) message() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	switch e.status() {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusForbidden:
+		return "forbidden"
+	}
+	return "error"
+}
+
+// JSONErrorFrom returns an error response for err on the http response. If err is or wraps an *APIError, its status code, message, debug detail and data are used, otherwise an internal server error is returned without exposing err to the client
+func JSONErrorFrom(w http.ResponseWriter, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		JSONInternalError(w, "", "")
+		return
+	}
+	JSONError(w, apiErr.Data, apiErr.message(), apiErr.Debug, apiErr.status())
+}
@@ -0,0 +1,93 @@
+package jsonhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONErrorFrom(t *testing.T) {
+	cause := errors.New("sql: no rows in result set")
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{
+			"APIError",
+			NotFoundError("", "user 42").WithData([]string{"id"}),
+			http.StatusNotFound,
+			`{"message":"not_found","success":false,"data":["id"],"debug":"user 42"}`,
+		},
+		{
+			"wrapped APIError",
+			fmt.Errorf("loading user: %w", ForbiddenError("no_access", "").Wrap(cause)),
+			http.StatusForbidden,
+			`{"message":"no_access","success":false,"data":null}`,
+		},
+		{
+			"plain error",
+			cause,
+			http.StatusInternalServerError,
+			`{"message":"error","success":false,"data":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		JSONErrorFrom(w, tt.err)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status should be %d, got %d", tt.name, tt.status, w.Code)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: body should be %s, got %s", tt.name, tt.body, w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), "sql") {
+			t.Errorf("%s: the cause should not be sent to the client, got %s", tt.name, w.Body.String())
+		}
+	}
+}
+
+func TestAPIErrorDefaults(t *testing.T) {
+	tests := []struct {
+		err     *APIError
+		status  int
+		message string
+	}{
+		{BadRequestError("", ""), http.StatusBadRequest, "bad_request"},
+		{NotFoundError("", ""), http.StatusNotFound, "not_found"},
+		{ForbiddenError("", ""), http.StatusForbidden, "forbidden"},
+		{InternalError("", ""), http.StatusInternalServerError, "error"},
+		{NewAPIError(http.StatusConflict, "", ""), http.StatusConflict, "error"},
+		{&APIError{}, http.StatusInternalServerError, "error"},
+		{NotFoundError("no_user", ""), http.StatusNotFound, "no_user"},
+	}
+
+	for _, tt := range tests {
+		if tt.err.status() != tt.status || tt.err.message() != tt.message {
+			t.Errorf("%#v should default to %d %s, got %d %s", tt.err, tt.status, tt.message, tt.err.status(), tt.err.message())
+		}
+	}
+}
+
+func TestAPIErrorWrap(t *testing.T) {
+	cause := errors.New("timeout")
+	base := InternalError("", "db")
+	err := base.Wrap(cause)
+
+	if !errors.Is(err, cause) {
+		t.Errorf("Wrapped APIError should match its cause")
+	}
+	if base.Err != nil {
+		t.Errorf("Wrap should not modify the original APIError")
+	}
+	if want := "error: db: timeout"; err.Error() != want {
+		t.Errorf("Error should be %q, got %q", want, err.Error())
+	}
+}
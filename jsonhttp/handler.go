@@ -0,0 +1,61 @@
+package jsonhttp
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// HandlerFunc is the signature of the functions adapted by Handle. It receives the request context and the decoded request payload and returns the response data or an error
+type HandlerFunc[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+// Handle adapts fn into an http.HandlerFunc that decodes and checks the request body into Req like JSONDecodeAndCatchForAPI. The returned data is sent with JSONSuccess and errors are sent with JSONErrorFrom
+func Handle[Req, Resp any](fn HandlerFunc[Req, Resp]) http.HandlerFunc {
+	return HandleWith(DecodeOptions{}, fn)
+}
+
+// HandleWith is Handle with the request body decoded with the provided options. It panics if the `validate` tags of Req have invalid rules, see CheckValidateTags
+func HandleWith[Req, Resp any](opts DecodeOptions, fn HandlerFunc[Req, Resp]) http.HandlerFunc {
+	if err := CheckValidateTags(new(Req)); err != nil {
+		panic(err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		// Checkable requests are usually implemented on the pointer, so check &req unless Req already is one
+		target := interface{}(&req)
+		if v := reflect.ValueOf(&req).Elem(); v.Kind() == reflect.Ptr {
+			v.Set(reflect.New(v.Type().Elem()))
+			target = req
+		}
+
+		hasBody := r.Body != nil && r.Body != http.NoBody
+		if hasBody {
+			if err := opts.decodeBody(w, r, target); err != nil {
+				JSONErrorFrom(w, decodeError(err))
+				return
+			}
+		}
+		if hasBody || !isBodylessMethod(r.Method) {
+			if err := checkParameters(target); err != nil {
+				JSONErrorFrom(w, parametersError(err))
+				return
+			}
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			JSONErrorFrom(w, err)
+			return
+		}
+		JSONSuccess(w, resp, "")
+	}
+}
+
+// isBodylessMethod reports whether requests with the method usually have no payload
+func isBodylessMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package jsonhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+func (r *greetRequest) Parameters() error {
+	if r.Name == "" {
+		return ValidationErrors{{Field: "name", Code: "required", Message: "is required"}}
+	}
+	return nil
+}
+
+type greetResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+func greet(ctx context.Context, req greetRequest) (greetResponse, error) {
+	if req.Name == "nobody" {
+		return greetResponse{}, NotFoundError("", "")
+	}
+	return greetResponse{Greeting: "hello " + req.Name}, nil
+}
+
+func greetPointer(ctx context.Context, req *greetRequest) (greetResponse, error) {
+	return greet(ctx, *req)
+}
+
+func serveHandle(h http.Handler, method string, body string) *httptest.ResponseRecorder {
+	var r *http.Request
+	if body == "" {
+		r = httptest.NewRequest(method, "/", nil)
+	} else {
+		r = httptest.NewRequest(method, "/", strings.NewReader(body))
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandle(t *testing.T) {
+	handlers := map[string]http.Handler{
+		"value":   Handle(greet),
+		"pointer": Handle(greetPointer),
+	}
+
+	tests := []struct {
+		method string
+		body   string
+		status int
+		resp   string
+	}{
+		{"POST", `{"name":"gopher"}`, http.StatusOK, `{"message":"ok","success":true,"data":{"greeting":"hello gopher"}}`},
+		{"POST", `{"name":""}`, http.StatusUnprocessableEntity, `{"message":"validation_failed","success":false,"data":[{"field":"name","code":"required","message":"is required"}]}`},
+		{"POST", ``, http.StatusUnprocessableEntity, `{"message":"validation_failed","success":false,"data":[{"field":"name","code":"required","message":"is required"}]}`},
+		{"POST", `{"name":`, http.StatusBadRequest, `{"message":"Invalid JSON","success":false,"data":null}`},
+		{"POST", `{"name":"nobody"}`, http.StatusNotFound, `{"message":"not_found","success":false,"data":null}`},
+		// Requests without a payload are not checked, so the handler reads its input elsewhere
+		{"GET", ``, http.StatusOK, `{"message":"ok","success":true,"data":{"greeting":"hello "}}`},
+	}
+
+	for name, h := range handlers {
+		for _, tt := range tests {
+			w := serveHandle(h, tt.method, tt.body)
+			if w.Code != tt.status {
+				t.Errorf("%s: %s %s should respond %d, got %d", name, tt.method, tt.body, tt.status, w.Code)
+			}
+			if w.Body.String() != tt.resp {
+				t.Errorf("%s: %s %s should respond %s, got %s", name, tt.method, tt.body, tt.resp, w.Body.String())
+			}
+		}
+	}
+}
+
+func TestHandleUnknownError(t *testing.T) {
+	h := Handle(func(ctx context.Context, req struct{}) (struct{}, error) {
+		return struct{}{}, errors.New("connection refused")
+	})
+
+	w := serveHandle(h, "POST", `{}`)
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "refused") {
+		t.Errorf("Unknown errors should respond 500 without details, got %d %s", w.Code, w.Body.String())
+	}
+}
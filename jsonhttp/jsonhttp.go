@@ -3,6 +3,7 @@ package jsonhttp
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"reflect"
 )
@@ -15,6 +16,33 @@ type APIResponse struct {
 	Debug   string      `json:"debug,omitempty"`
 }
 
+// WithErrorHook returns a middleware that reports the errors the response functions can't return to the wrapped handler, like payloads that fail to marshal, to hook instead of the standard logger
+func WithErrorHook(hook func(err error)) func(http.Handler) http.HandlerFunc {
+	return func(handler http.Handler) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			sw := withSettings(w)
+			sw.settings.errorHook = hook
+			handler.ServeHTTP(sw, r)
+		}
+	}
+}
+
+// reportError passes err to the error hook selected for w, logging it if there is none
+func reportError(w http.ResponseWriter, err error) {
+	if hook := settingsFrom(w).errorHook; hook != nil {
+		hook(err)
+		return
+	}
+	log.Print(err)
+}
+
+var (
+	// internalErrorResponse is the APIResponse sent by JSONInternalError without a message or debug detail
+	internalErrorResponse = []byte(`{"message":"error","success":false,"data":null}`)
+	// internalErrorProblem is the problem details version of internalErrorResponse
+	internalErrorProblem = []byte(`{"status":500,"title":"Internal Server Error"}`)
+)
+
 // CheckableRequest defines an interface for request payloads that can be checked with the jsonhttp checker. See JSONDecodeAndCatchForAPI for the usage
 type CheckableRequest interface {
 	Parameters() error
@@ -44,7 +72,7 @@ func JSONError(w http.ResponseWriter, data interface{}, message string, debug st
 		Data:    data,
 		Debug:   debug,
 	}
-	JSONWriter(w, resp, statusCode)
+	writeError(w, resp, statusCode)
 }
 
 // JSONInternalError returns an internal server error APIResponse on the http response with the provided parameters
@@ -58,7 +86,7 @@ func JSONInternalError(w http.ResponseWriter, message string, debug string) {
 		Data:    nil,
 		Debug:   debug,
 	}
-	JSONWriter(w, resp, http.StatusInternalServerError)
+	writeError(w, resp, http.StatusInternalServerError)
 }
 
 // JSONInternalError returns a bad request error APIResponse on the http response with the provided parameters
@@ -72,7 +100,7 @@ func JSONBadRequestError(w http.ResponseWriter, message string, debug string) {
 		Data:    nil,
 		Debug:   debug,
 	}
-	JSONWriter(w, resp, http.StatusBadRequest)
+	writeError(w, resp, http.StatusBadRequest)
 }
 
 // JSONNotFoundError returns a not found error APIResponse on the http response with the provided parameters
@@ -86,7 +114,7 @@ func JSONNotFoundError(w http.ResponseWriter, message string, debug string) {
 		Data:    nil,
 		Debug:   debug,
 	}
-	JSONWriter(w, resp, http.StatusNotFound)
+	writeError(w, resp, http.StatusNotFound)
 }
 
 // JSONForbiddenError returns a forbidden error APIResponse on the http response with the provided parameters
@@ -100,7 +128,7 @@ func JSONForbiddenError(w http.ResponseWriter, message string, debug string) {
 		Data:    nil,
 		Debug:   debug,
 	}
-	JSONWriter(w, resp, http.StatusForbidden)
+	writeError(w, resp, http.StatusForbidden)
 }
 
 // JSONDetailed returns the provided APIResponse on the http response with the provided HTTP status code
@@ -111,55 +139,77 @@ func JSONDetailed(w http.ResponseWriter, resp APIResponse, statusCode int) {
 	JSONWriter(w, resp, statusCode)
 }
 
-// JSONWriter provides a wrapper function to marshal an interface{} type to JSON and then send the bytes back over an http.ResponseWriter
+// JSONWriter provides a wrapper function to marshal an interface{} type to JSON and then send the bytes back over an http.ResponseWriter. The content type and indentation are negotiated by the Negotiate middleware, if used
 func JSONWriter(w http.ResponseWriter, payload interface{}, statusCode int) {
-	//dj, err := json.MarshalIndent(payload, "", "  ")
-	dj, err := json.Marshal(payload)
-	if err != nil {
-		http.Error(w, "Error creating JSON response", http.StatusInternalServerError)
-		fmt.Println(err)
+	writeJSON(w, payload, statusCode, contentTypeFor(w))
+}
+
+// writeJSON marshals the payload with the negotiated indentation and sends it with the provided content type
+func writeJSON(w http.ResponseWriter, payload interface{}, statusCode int, contentType string) {
+	dj, ok := marshalJSON(w, payload)
+	if !ok {
+		return
 	}
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(statusCode)
-	fmt.Fprintf(w, "%s", dj)
+	w.Write(dj)
 }
 
-// JSONDecodeAndCatchForAPI is the primary function for decoding checkable (and non-checkable) payloads into structs. If the struct passed into `outStruct` satisfied the `CheckableRequest` interface, the check will also be run after decoding the JSON
-func JSONDecodeAndCatchForAPI(w http.ResponseWriter, r *http.Request, outStruct interface{}) error {
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&outStruct)
-	if err != nil {
-		JSONBadRequestError(w, "Invalid JSON", "")
-		return err
-	}
-	if !isCheckableRequest(outStruct) {
-		return nil
+// marshalJSON marshals the payload with the negotiated indentation. If marshaling fails, the failure is reported to the error hook (see WithErrorHook) and sent on the http response, and ok is false
+func marshalJSON(w http.ResponseWriter, payload interface{}) (dj []byte, ok bool) {
+	var err error
+	if indent := settingsFrom(w).indent; indent != "" {
+		dj, err = json.MarshalIndent(payload, "", indent)
+	} else {
+		dj, err = json.Marshal(payload)
 	}
-	method := reflect.ValueOf(outStruct).MethodByName("Parameters").Interface().(func() error)
-	err = method()
 	if err != nil {
-		JSONBadRequestError(w, "", err.Error())
-		return err
+		// Nothing has been committed yet, so the failure can still be reported properly
+		reportError(w, fmt.Errorf("jsonhttp: marshal response: %w", err))
+		writeMarshalFailure(w)
+		return nil, false
 	}
-	return nil
+	return dj, true
 }
 
-// JSONDecodeNoCatch decodes checkable (and non-checkable) payloads into structs. If the struct passed into `outStruct` satisfied the `CheckableRequest` interface, the check will also be run after decoding the JSON
-func JSONDecodeNoCatch(r *http.Request, outStruct interface{}) error {
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&outStruct)
-	if err != nil {
-		return err
+// writeMarshalFailure sends a fixed internal server error in the error format selected for the handler, so the response is well-formed although the payload could not be marshaled
+func writeMarshalFailure(w http.ResponseWriter) {
+	contentType, body := contentTypeFor(w), internalErrorResponse
+	if errorFormatFor(w) == ErrorFormatProblem {
+		contentType, body = ProblemContentType, internalErrorProblem
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write(body)
+}
+
+// writeError writes an error APIResponse in the error format selected for the handler
+func writeError(w http.ResponseWriter, resp APIResponse, statusCode int) {
+	if errorFormatFor(w) == ErrorFormatProblem {
+		JSONProblem(w, problemFromResponse(resp, statusCode))
+		return
 	}
+	JSONWriter(w, resp, statusCode)
+}
+
+// JSONDecodeAndCatchForAPI is the primary function for decoding checkable (and non-checkable) payloads into structs. After decoding the JSON, the `validate` struct tags are checked (see Validate) and so is the `CheckableRequest` interface, if `outStruct` satisfies it
+func JSONDecodeAndCatchForAPI(w http.ResponseWriter, r *http.Request, outStruct interface{}) error {
+	return DecodeOptions{}.DecodeAndCatchForAPI(w, r, outStruct)
+}
+
+// JSONDecodeNoCatch decodes checkable (and non-checkable) payloads into structs, checking the `validate` struct tags and the `CheckableRequest` interface after decoding the JSON
+func JSONDecodeNoCatch(r *http.Request, outStruct interface{}) error {
+	return DecodeOptions{}.Decode(r, outStruct)
+}
+
+// checkParameters validates the `validate` struct tags of outStruct and runs the CheckableRequest check if outStruct satisfies the interface, merging the results. See Validate for the supported tags
+func checkParameters(outStruct interface{}) error {
+	tagErrs := Validate(outStruct)
 	if !isCheckableRequest(outStruct) {
-		return nil
+		return mergeValidation(tagErrs, nil)
 	}
 	method := reflect.ValueOf(outStruct).MethodByName("Parameters").Interface().(func() error)
-	err = method()
-	if err != nil {
-		return err
-	}
-	return nil
+	return mergeValidation(tagErrs, method())
 }
 
 func isCheckableRequest(checkAgainst interface{}) bool {
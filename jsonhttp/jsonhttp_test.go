@@ -0,0 +1,70 @@
+package jsonhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// headerCounter counts the WriteHeader calls of a handler
+type headerCounter struct {
+	*httptest.ResponseRecorder
+	writeHeaders int
+}
+
+func (c *headerCounter) WriteHeader(statusCode int) {
+	c.writeHeaders++
+	c.ResponseRecorder.WriteHeader(statusCode)
+}
+
+func TestMarshalFailure(t *testing.T) {
+	unmarshalable := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		JSONSuccess(w, map[string]interface{}{"ch": make(chan int)}, "")
+	})
+
+	tests := []struct {
+		name        string
+		wrap        func(http.Handler) http.HandlerFunc
+		contentType string
+		body        string
+	}{
+		{"envelope", WithErrorFormat(ErrorFormatEnvelope), "application/json", `{"message":"error","success":false,"data":null}`},
+		{"problem", ProblemDetails, ProblemContentType, `{"status":500,"title":"Internal Server Error"}`},
+	}
+
+	for _, tt := range tests {
+		var reported []error
+		h := WithErrorHook(func(err error) { reported = append(reported, err) })(tt.wrap(unmarshalable))
+
+		w := &headerCounter{ResponseRecorder: httptest.NewRecorder()}
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.writeHeaders != 1 || w.Code != http.StatusInternalServerError {
+			t.Errorf("%s: a single 500 status should be written, got %d calls and %d", tt.name, w.writeHeaders, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s: Content-Type should be %s, got %s", tt.name, tt.contentType, ct)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: body should be %s, got %s", tt.name, tt.body, w.Body.String())
+		}
+		if len(reported) != 1 || !strings.Contains(reported[0].Error(), "marshal response") {
+			t.Errorf("%s: the marshal error should be reported to the hook once, got %v", tt.name, reported)
+		}
+	}
+}
+
+func TestErrorHookInherited(t *testing.T) {
+	var reported error
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		JSONWriter(w, make(chan int), http.StatusOK)
+	})
+
+	// Inner middlewares keep the hook of outer ones unless they set their own
+	w := httptest.NewRecorder()
+	WithErrorHook(func(err error) { reported = err })(ProblemDetails(inner)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if reported == nil {
+		t.Errorf("The hook should be kept by inner middlewares")
+	}
+}
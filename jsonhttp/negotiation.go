@@ -0,0 +1,162 @@
+package jsonhttp
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Negotiation configures the content negotiation of JSON responses. See WithNegotiation for the usage
+type Negotiation struct {
+	// MediaTypes lists the JSON media types the service produces in order of preference, e.g. application/vnd.api+json or a custom vendor type. Defaults to application/json
+	MediaTypes []string
+	// PrettyParam is the query parameter that enables indentation, e.g. ?pretty=1. Defaults to "pretty"
+	PrettyParam string
+	// Indent is the indentation used for ?pretty=1. Defaults to two spaces
+	Indent string
+}
+
+// maxIndent limits the indentation clients can request with the indent media type parameter
+const maxIndent = 8
+
+// Negotiate is a middleware that negotiates the JSON responses of the wrapped handler with the zero Negotiation, which produces application/json. Use WithNegotiation for other media types
+func Negotiate(handler http.Handler) http.HandlerFunc {
+	return WithNegotiation(Negotiation{})(handler)
+}
+
+// WithNegotiation returns a middleware that negotiates the content type and indentation of the JSON responses of the wrapped handler from the Accept header, answering 406 Not Acceptable if it accepts none of n.MediaTypes
+func WithNegotiation(n Negotiation) func(http.Handler) http.HandlerFunc {
+	return func(handler http.Handler) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept")
+
+			mediaType, indent, ok := n.negotiate(r.Header.Get("Accept"))
+			if !ok {
+				JSONError(w, n.mediaTypes(), "not_acceptable", "", http.StatusNotAcceptable)
+				return
+			}
+			if indent == "" && n.pretty(r) {
+				indent = n.Indent
+				if indent == "" {
+					indent = "  "
+				}
+			}
+
+			sw := withSettings(w)
+			sw.settings.contentType = mediaType
+			sw.settings.indent = indent
+			handler.ServeHTTP(sw, r)
+		}
+	}
+}
+
+func (n Negotiation) mediaTypes() []string {
+	if len(n.MediaTypes) == 0 {
+		return []string{"application/json"}
+	}
+	return n.MediaTypes
+}
+
+// pretty reports whether the request enables indentation with the PrettyParam query parameter. A parameter without a value counts as enabled
+func (n Negotiation) pretty(r *http.Request) bool {
+	param := n.PrettyParam
+	if param == "" {
+		param = "pretty"
+	}
+	values, ok := r.URL.Query()[param]
+	if !ok {
+		return false
+	}
+	if len(values) == 0 || values[0] == "" {
+		return true
+	}
+	pretty, err := strconv.ParseBool(values[0])
+	return err == nil && pretty
+}
+
+// negotiate returns the produced media type the Accept header prefers and the indentation it requests. Ties are broken by the order of MediaTypes. ok is false if the header accepts none of them
+func (n Negotiation) negotiate(accept string) (mediaType string, indent string, ok bool) {
+	mediaTypes := n.mediaTypes()
+	if strings.TrimSpace(accept) == "" {
+		return mediaTypes[0], "", true
+	}
+
+	ranges := parseAccept(accept)
+	bestQ := 0.0
+	for _, produced := range mediaTypes {
+		match, found := bestMediaRange(ranges, produced)
+		if !found || match.q <= bestQ {
+			continue
+		}
+		bestQ = match.q
+		mediaType = produced
+		indent = ""
+		if spaces, err := strconv.Atoi(match.params["indent"]); err == nil && spaces > 0 {
+			indent = strings.Repeat(" ", min(spaces, maxIndent))
+		}
+	}
+	return mediaType, indent, mediaType != ""
+}
+
+// mediaRange is a media range of an Accept header
+type mediaRange struct {
+	mediaType string
+	q         float64
+	params    map[string]string
+}
+
+// parseAccept parses the media ranges of an Accept header, skipping invalid ones
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		part = strings.TrimSpace(part)
+		if part == "*" {
+			part = "*/*"
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q, params: params})
+	}
+	return ranges
+}
+
+// bestMediaRange returns the most specific media range matching the media type, as its quality applies
+func bestMediaRange(ranges []mediaRange, mediaType string) (mediaRange, bool) {
+	mediaType = strings.ToLower(mediaType)
+	main, _, _ := strings.Cut(mediaType, "/")
+
+	best := mediaRange{}
+	bestSpecificity := 0
+	for _, r := range ranges {
+		specificity := 0
+		switch r.mediaType {
+		case mediaType:
+			specificity = 3
+		case main + "/*":
+			specificity = 2
+		case "*/*":
+			specificity = 1
+		}
+		if specificity > bestSpecificity {
+			best, bestSpecificity = r, specificity
+		}
+	}
+	return best, bestSpecificity > 0
+}
+
+// contentTypeFor returns the negotiated JSON media type for w
+func contentTypeFor(w http.ResponseWriter) string {
+	if contentType := settingsFrom(w).contentType; contentType != "" {
+		return contentType
+	}
+	return "application/json"
+}
@@ -0,0 +1,120 @@
+package jsonhttp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseAccept(t *testing.T) {
+	got := parseAccept(`application/json;q=0.5, text/*; charset="utf-8", *, invalid/, application/xml;q=high`)
+	want := []mediaRange{
+		{mediaType: "application/json", q: 0.5, params: map[string]string{"q": "0.5"}},
+		{mediaType: "text/*", q: 1, params: map[string]string{"charset": "utf-8"}},
+		{mediaType: "*/*", q: 1, params: map[string]string{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parsed media ranges should be %v, got %v", want, got)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	n := Negotiation{MediaTypes: []string{"application/vnd.api+json", "application/json"}}
+
+	tests := []struct {
+		accept    string
+		mediaType string
+		indent    string
+		ok        bool
+	}{
+		{"", "application/vnd.api+json", "", true},
+		{"application/json", "application/json", "", true},
+		{"*/*", "application/vnd.api+json", "", true},
+		{"*", "application/vnd.api+json", "", true},
+		{"application/*;q=0.5, application/json", "application/json", "", true},
+		{"application/json;q=0.9, application/vnd.api+json;q=0.9", "application/vnd.api+json", "", true},
+		// the most specific range applies, even with a lower quality
+		{"application/vnd.api+json;q=0.1, application/*;q=0.8", "application/json", "", true},
+		{"application/vnd.api+json;q=0, */*;q=0.3", "application/json", "", true},
+		{"APPLICATION/JSON", "application/json", "", true},
+		{"application/json; indent=4", "application/json", "    ", true},
+		{"application/json; indent=100", "application/json", "        ", true},
+		{"application/json; indent=-2", "application/json", "", true},
+		{"text/html", "", "", false},
+		{"application/json;q=0, application/vnd.api+json;q=0", "", "", false},
+		{"application/json;q=high", "", "", false},
+	}
+
+	for _, tt := range tests {
+		mediaType, indent, ok := n.negotiate(tt.accept)
+		if mediaType != tt.mediaType || indent != tt.indent || ok != tt.ok {
+			t.Errorf("Accept %q should negotiate %q %q %v, got %q %q %v", tt.accept, tt.mediaType, tt.indent, tt.ok, mediaType, indent, ok)
+		}
+	}
+}
+
+func TestWithNegotiation(t *testing.T) {
+	handler := Negotiate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		JSONSuccess(w, []int{1}, "")
+	}))
+
+	tests := []struct {
+		target      string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/", "", http.StatusOK, "application/json", `{"message":"ok","success":true,"data":[1]}`},
+		{"/?pretty", "", http.StatusOK, "application/json", "{\n  \"message\": \"ok\",\n  \"success\": true,\n  \"data\": [\n    1\n  ]\n}"},
+		{"/?pretty=false", "", http.StatusOK, "application/json", `{"message":"ok","success":true,"data":[1]}`},
+		{"/?pretty=1", "application/json; indent=1", http.StatusOK, "application/json", "{\n \"message\": \"ok\",\n \"success\": true,\n \"data\": [\n  1\n ]\n}"},
+		{"/", "text/html", http.StatusNotAcceptable, "application/json", `{"message":"not_acceptable","success":false,"data":["application/json"]}`},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.target, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != tt.status || w.Header().Get("Content-Type") != tt.contentType || w.Body.String() != tt.body {
+			t.Errorf("%s with Accept %q should respond %d %s %s, got %d %s %s", tt.target, tt.accept, tt.status, tt.contentType, tt.body, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+		if w.Header().Get("Vary") != "Accept" {
+			t.Errorf("%s: responses should vary on Accept", tt.target)
+		}
+	}
+}
+
+// hijackRecorder is a ResponseRecorder that supports hijacking
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+func TestSettingsWriterInterfaces(t *testing.T) {
+	var sw http.ResponseWriter = withSettings(httptest.NewRecorder())
+	if _, _, err := sw.(http.Hijacker).Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Hijack should return http.ErrNotSupported, got %v", err)
+	}
+	if err := sw.(http.Pusher).Push("/style.css", nil); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Push should return http.ErrNotSupported, got %v", err)
+	}
+
+	h := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	if _, _, err := http.NewResponseController(withSettings(h)).Hijack(); err != nil || !h.hijacked {
+		t.Errorf("Hijack should be forwarded, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package jsonhttp
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/exlinc/golang-utils/queryparams"
+)
+
+// PaginationMeta describes the page of a paginated response. Next and Prev are links to the neighbouring pages, relative to the request host
+type PaginationMeta struct {
+	Limit  int64  `json:"limit"`
+	Offset int64  `json:"offset"`
+	Total  int64  `json:"total"`
+	Next   string `json:"next,omitempty"`
+	Prev   string `json:"prev,omitempty"`
+}
+
+// PaginatedResponse is the APIResponse with a meta block sent by JSONPaginated
+type PaginatedResponse struct {
+	APIResponse
+	Meta PaginationMeta `json:"meta"`
+}
+
+// JSONPaginated returns a successful APIResponse with the page of items as data and a meta block describing the page on the http response, linking the neighbouring pages in the meta block and a Link header
+func JSONPaginated(w http.ResponseWriter, r *http.Request, items interface{}, total int64, limit int64, offset int64) {
+	meta := PaginationMeta{
+		Limit:  limit,
+		Offset: offset,
+		Total:  total,
+	}
+	if limit > 0 {
+		if offset+limit < total {
+			meta.Next = pageURL(r, limit, offset+limit)
+		}
+		if offset > 0 {
+			// Past the end, prev points to the last page rather than to another empty one
+			lastOffset := int64(0)
+			if total > 0 {
+				lastOffset = (total - 1) / limit * limit
+			}
+			meta.Prev = pageURL(r, limit, max(min(offset-limit, lastOffset), 0))
+		}
+	}
+
+	if meta.Next != "" {
+		w.Header().Add("Link", "<"+meta.Next+`>; rel="next"`)
+	}
+	if meta.Prev != "" {
+		w.Header().Add("Link", "<"+meta.Prev+`>; rel="prev"`)
+	}
+
+	resp := PaginatedResponse{
+		APIResponse: APIResponse{
+			Message: "ok",
+			Success: true,
+			Data:    items,
+		},
+		Meta: meta,
+	}
+	JSONWriter(w, resp, http.StatusOK)
+}
+
+// pageURL returns the request URL, without scheme and host, with the limit and offset of another page
+func pageURL(r *http.Request, limit int64, offset int64) string {
+	u := *r.URL
+	u.Scheme, u.Host, u.User, u.Fragment = "", "", nil, ""
+	q := u.Query()
+	q.Set(queryparams.LimitParameter, strconv.FormatInt(limit, 10))
+	q.Set(queryparams.OffsetParameter, strconv.FormatInt(offset, 10))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,55 @@
+package jsonhttp
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestJSONPaginated(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		total  int64
+		limit  int64
+		offset int64
+		next   string
+		prev   string
+	}{
+		{"first page", "/items", 25, 10, 0, "/items?limit=10&offset=10", ""},
+		{"middle page", "/items", 25, 10, 10, "/items?limit=10&offset=20", "/items?limit=10&offset=0"},
+		{"last page", "/items", 25, 10, 20, "", "/items?limit=10&offset=10"},
+		{"unaligned offset", "/items", 25, 10, 5, "/items?limit=10&offset=15", "/items?limit=10&offset=0"},
+		{"offset past total", "/items", 25, 10, 50, "", "/items?limit=10&offset=20"},
+		{"offset past empty list", "/items", 0, 10, 30, "", "/items?limit=10&offset=0"},
+		{"single page", "/items", 5, 10, 0, "", ""},
+		{"limit 0", "/items", 25, 0, 10, "", ""},
+		{"other params", "http://example.com/items?sort=name&limit=10&offset=10#top", 25, 10, 10, "/items?limit=10&offset=20&sort=name", "/items?limit=10&offset=0&sort=name"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		JSONPaginated(w, httptest.NewRequest("GET", tt.target, nil), []int{}, tt.total, tt.limit, tt.offset)
+
+		var resp PaginatedResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: error unmarshaling %s: %s", tt.name, w.Body.String(), err)
+		}
+		want := PaginationMeta{Limit: tt.limit, Offset: tt.offset, Total: tt.total, Next: tt.next, Prev: tt.prev}
+		if resp.Meta != want {
+			t.Errorf("%s: meta should be %+v, got %+v", tt.name, want, resp.Meta)
+		}
+
+		var links []string
+		if tt.next != "" {
+			links = append(links, "<"+tt.next+`>; rel="next"`)
+		}
+		if tt.prev != "" {
+			links = append(links, "<"+tt.prev+`>; rel="prev"`)
+		}
+		if got := w.Header().Values("Link"); !reflect.DeepEqual(got, links) {
+			t.Errorf("%s: Link header should be %v, got %v", tt.name, links, got)
+		}
+	}
+}
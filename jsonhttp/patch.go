@@ -0,0 +1,654 @@
+package jsonhttp
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types of the patch documents accepted by DecodePatch
+const (
+	JSONPatchContentType  = "application/json-patch+json"
+	MergePatchContentType = "application/merge-patch+json"
+)
+
+var (
+	// ErrInvalidPatch is returned for patch documents that are malformed or can't be applied, like operations on paths that do not exist
+	ErrInvalidPatch = errors.New("jsonhttp: invalid patch")
+	// ErrPatchTestFailed is returned when a test operation of a JSON Patch does not match the target
+	ErrPatchTestFailed = errors.New("jsonhttp: patch test failed")
+)
+
+// JSONDecodePatch applies the patch in the request body to target like JSONDecodeNoCatch. See DecodeOptions.DecodePatch
+func JSONDecodePatch(r *http.Request, target interface{}) error {
+	return DecodeOptions{}.DecodePatch(r, target)
+}
+
+// JSONDecodePatchAndCatchForAPI applies the patch in the request body to target like JSONDecodeAndCatchForAPI. See DecodeOptions.DecodePatch
+func JSONDecodePatchAndCatchForAPI(w http.ResponseWriter, r *http.Request, target interface{}) error {
+	return DecodeOptions{}.DecodePatchAndCatchForAPI(w, r, target)
+}
+
+// DecodePatch applies the JSON Patch or JSON Merge Patch in the request body, selected by the Content-Type, to the existing value target points to, then checks the result like Decode
+func (o DecodeOptions) DecodePatch(r *http.Request, target interface{}) error {
+	err := o.decodePatch(nil, r, target)
+	if err != nil {
+		return err
+	}
+	return checkParameters(target)
+}
+
+// DecodePatchAndCatchForAPI is DecodePatch, sending the matching error response when it fails like DecodeAndCatchForAPI. Invalid patches are answered with 422 Unprocessable Entity and failed test operations with 409 Conflict
+func (o DecodeOptions) DecodePatchAndCatchForAPI(w http.ResponseWriter, r *http.Request, target interface{}) error {
+	err := o.decodePatch(w, r, target)
+	if err != nil {
+		JSONErrorFrom(w, decodeError(err))
+		return err
+	}
+	err = checkParameters(target)
+	if err != nil {
+		JSONErrorFrom(w, parametersError(err))
+		return err
+	}
+	return nil
+}
+
+// decodePatch reads the patch in the request body and applies it to target
+func (o DecodeOptions) decodePatch(w http.ResponseWriter, r *http.Request, target interface{}) error {
+	mediaType := ""
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		var err error
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return ErrUnsupportedMediaType
+		}
+	}
+	if mediaType != "" && mediaType != JSONPatchContentType && mediaType != MergePatchContentType && mediaType != "application/json" {
+		return ErrUnsupportedMediaType
+	}
+
+	var patch json.RawMessage
+	if err := o.decodeBody(w, r, &patch); err != nil {
+		return err
+	}
+	if mediaType == JSONPatchContentType {
+		return applyPatch(target, func(doc interface{}) (interface{}, error) {
+			return applyJSONPatch(doc, patch)
+		}, o.DisallowUnknownFields)
+	}
+	return applyPatch(target, func(doc interface{}) (interface{}, error) {
+		return applyMergePatch(doc, patch, reflect.TypeOf(target))
+	}, o.DisallowUnknownFields)
+}
+
+// ApplyJSONPatch applies a JSON Patch (RFC 6902) to the value target points to. The operations are applied in order to the JSON encoding of the value, and target is only modified if all of them succeed and the result decodes into it
+func ApplyJSONPatch(target interface{}, patch []byte) error {
+	return applyPatch(target, func(doc interface{}) (interface{}, error) {
+		return applyJSONPatch(doc, patch)
+	}, false)
+}
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7396) to the value target points to. Members set to null in the patch are removed, which sets the matching fields to their zero value. target is only modified if the result decodes into it
+func ApplyMergePatch(target interface{}, patch []byte) error {
+	return applyPatch(target, func(doc interface{}) (interface{}, error) {
+		return applyMergePatch(doc, patch, reflect.TypeOf(target))
+	}, false)
+}
+
+// applyPatch applies a patch function to the JSON document of the value target points to and decodes the result back into it. Fields that are not encoded to JSON keep their value, including those of nested and embedded structs
+func applyPatch(target interface{}, patch func(doc interface{}) (interface{}, error), disallowUnknownFields bool) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("jsonhttp: patch target must be a non-nil pointer")
+	}
+
+	original, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeDocument(original)
+	if err != nil {
+		return err
+	}
+	doc, err = patch(doc)
+	if err != nil {
+		return err
+	}
+	// Members added with another case would be decoded with the original ones, so which wins depends on their order
+	if _, err := canonicalMembers(doc, v.Elem().Type(), true); err != nil {
+		return err
+	}
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	// Decode into a copy, so fields removed by the patch end up zero and target is untouched on errors
+	result := reflect.New(v.Elem().Type())
+	result.Elem().Set(v.Elem())
+	prepareForPatch(result.Elem(), doc)
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	if disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(result.Interface()); err != nil {
+		return err
+	}
+	v.Elem().Set(result.Elem())
+	return nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// prepareForPatch readies v, a copy of the patched value, for decoding doc onto it. Fields whose members doc removes are zeroed, and fields that are not encoded to JSON keep their value at any depth
+func prepareForPatch(v reflect.Value, doc interface{}) {
+	members, isObject := doc.(map[string]interface{})
+
+	switch {
+	case v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct && isObject && !decodesItself(v.Elem().Type()):
+		// prepare a copy, the original value still points to the struct
+		c := reflect.New(v.Elem().Type())
+		c.Elem().Set(v.Elem())
+		v.Set(c)
+		prepareForPatch(c.Elem(), doc)
+	case v.Kind() == reflect.Struct && isObject && !decodesItself(v.Type()):
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, ok := jsonFieldName(f)
+			if !ok {
+				continue
+			}
+			fv := v.Field(i)
+			if name == "" {
+				// embedded struct without a JSON name: its fields are promoted into the same object, and are settable even if the embedded type is not exported
+				if fv.Kind() == reflect.Struct || fv.CanSet() {
+					prepareForPatch(fv, doc)
+				}
+				continue
+			}
+			if !fv.CanSet() {
+				continue
+			}
+			if member, ok := members[name]; ok && member != nil {
+				prepareForPatch(fv, member)
+			} else {
+				fv.Set(reflect.Zero(f.Type))
+			}
+		}
+	case doc != nil && (v.Kind() == reflect.Bool || v.Kind() == reflect.String || isNumberKind(v.Kind())):
+		// decoding replaces them
+	default:
+		// decoding may update maps, slices, other pointers and custom unmarshalers in place, so they are decoded fresh
+		v.Set(reflect.Zero(v.Type()))
+	}
+}
+
+// decodesItself reports whether values of type t decode with a custom unmarshaler
+func decodesItself(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+// decodeDocument decodes JSON into generic values, keeping numbers exact
+func decodeDocument(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// applyMergePatch applies an RFC 7396 merge patch to doc, the JSON document of a value of type t. Members of the patch are matched to the fields of t ignoring case, like encoding/json does
+func applyMergePatch(doc interface{}, data []byte, t reflect.Type) (interface{}, error) {
+	patch, err := decodeDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	if patch, err = canonicalMembers(patch, t, false); err != nil {
+		return nil, err
+	}
+	return mergePatch(doc, patch), nil
+}
+
+// jsonMember is a member of the JSON object of a struct type
+type jsonMember struct {
+	name string
+	typ  reflect.Type
+}
+
+// jsonMembers returns the members of the JSON object of the struct type t in field order, including the promoted fields of embedded structs
+func jsonMembers(t reflect.Type) []jsonMember {
+	var members []jsonMember
+	seen := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		name, ok := jsonFieldName(f)
+		if !ok {
+			continue
+		}
+		if name != "" {
+			members = append(members, jsonMember{name, f.Type})
+			seen[name] = true
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		for _, m := range jsonMembers(ft) {
+			// fields of the outer struct hide promoted ones
+			if !seen[m.name] {
+				members = append(members, m)
+			}
+		}
+	}
+	return members
+}
+
+// canonicalMembers renames the members of the objects in doc that match a field of t only ignoring case, choosing fields like encoding/json. If strict is set, such members are an error instead
+func canonicalMembers(doc interface{}, t reflect.Type, strict bool) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	object, ok := doc.(map[string]interface{})
+	if !ok {
+		return doc, nil
+	}
+
+	switch {
+	case t.Kind() == reflect.Map:
+		for k, v := range object {
+			v, err := canonicalMembers(v, t.Elem(), strict)
+			if err != nil {
+				return nil, err
+			}
+			object[k] = v
+		}
+		return object, nil
+	case t.Kind() != reflect.Struct || decodesItself(t):
+		return doc, nil
+	}
+
+	members := jsonMembers(t)
+	result := make(map[string]interface{}, len(object))
+	for k, v := range object {
+		var member *jsonMember
+		for i := range members {
+			if members[i].name == k {
+				member = &members[i]
+				break
+			}
+		}
+		for i := 0; member == nil && i < len(members); i++ {
+			if strings.EqualFold(members[i].name, k) {
+				member = &members[i]
+			}
+		}
+		if member == nil {
+			result[k] = v
+			continue
+		}
+
+		if member.name != k {
+			if strict {
+				return nil, fmt.Errorf("%w: member %q only matches %q ignoring case", ErrInvalidPatch, k, member.name)
+			}
+			if _, ok := object[member.name]; ok {
+				return nil, fmt.Errorf("%w: members %q and %q set the same field", ErrInvalidPatch, k, member.name)
+			}
+		}
+		if _, ok := result[member.name]; ok {
+			return nil, fmt.Errorf("%w: more than one member sets %q", ErrInvalidPatch, member.name)
+		}
+		v, err := canonicalMembers(v, member.typ, strict)
+		if err != nil {
+			return nil, err
+		}
+		result[member.name] = v
+	}
+	return result, nil
+}
+
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = map[string]interface{}{}
+	}
+	for k, v := range members {
+		if v == nil {
+			delete(result, k)
+		} else {
+			result[k] = mergePatch(result[k], v)
+		}
+	}
+	return result
+}
+
+// patchOperation is an operation of an RFC 6902 JSON Patch
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch applies the operations of an RFC 6902 JSON Patch to doc in order
+func applyJSONPatch(doc interface{}, data []byte) (interface{}, error) {
+	var ops []patchOperation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	for i, op := range ops {
+		var err error
+		doc, err = op.apply(doc)
+		if err == errTestFailed {
+			return nil, fmt.Errorf("%w: operation %d at %q", ErrPatchTestFailed, i, *op.Path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+		}
+	}
+	return doc, nil
+}
+
+// errTestFailed is returned by the test operation and reported as ErrPatchTestFailed
+var errTestFailed = errors.New("test failed")
+
+func (op patchOperation) apply(doc interface{}) (interface{}, error) {
+	if op.Path == nil {
+		return nil, errors.New("missing path")
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%s without value", op.Op)
+		}
+		value, err := decodeDocument(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return addValue(doc, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			if doc, _, err = removeValue(doc, path); err != nil {
+				return nil, err
+			}
+			return addValue(doc, path, value)
+		}
+		current, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(current, value) {
+			return nil, errTestFailed
+		}
+		return doc, nil
+	case "remove":
+		doc, _, err = removeValue(doc, path)
+		return doc, err
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("%s without from", op.Op)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if op.Op == "move" {
+			if len(path) > len(from) && isPrefix(from, path) {
+				return nil, errors.New("move into its own child")
+			}
+			doc, value, err = removeValue(doc, from)
+		} else {
+			value, err = getValue(doc, from)
+			value = copyDocument(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid path %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex parses an array index token. "-" refers to the end of the array and is only allowed if end is true
+func arrayIndex(token string, length int, end bool) (int, error) {
+	if token == "-" && end {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	limit := length
+	if end {
+		limit++
+	}
+	if i >= limit {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := doc.(type) {
+		case map[string]interface{}:
+			v, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = container[i]
+		default:
+			return nil, fmt.Errorf("member %q not found", token)
+		}
+	}
+	return doc, nil
+}
+
+// addValue returns doc with value added at path. Values are inserted into arrays and replace existing object members
+func addValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, rest := path[0], path[1:]
+
+	switch container := doc.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			container[token] = value
+			return container, nil
+		}
+		child, ok := container[token]
+		if !ok {
+			return nil, fmt.Errorf("member %q not found", token)
+		}
+		child, err := addValue(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = child
+		return container, nil
+	case []interface{}:
+		if len(rest) == 0 {
+			i, err := arrayIndex(token, len(container), true)
+			if err != nil {
+				return nil, err
+			}
+			return append(container[:i], append([]interface{}{value}, container[i:]...)...), nil
+		}
+		i, err := arrayIndex(token, len(container), false)
+		if err != nil {
+			return nil, err
+		}
+		child, err := addValue(container[i], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		container[i] = child
+		return container, nil
+	}
+	return nil, fmt.Errorf("member %q not found", token)
+}
+
+// removeValue returns doc without the value at path, and the removed value
+func removeValue(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("can't remove the whole document")
+	}
+	token, rest := path[0], path[1:]
+
+	switch container := doc.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q not found", token)
+		}
+		if len(rest) == 0 {
+			delete(container, token)
+			return container, child, nil
+		}
+		child, removed, err := removeValue(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		container[token] = child
+		return container, removed, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(container), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := container[i]
+			return append(container[:i], container[i+1:]...), removed, nil
+		}
+		child, removed, err := removeValue(container[i], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		container[i] = child
+		return container, removed, nil
+	}
+	return nil, nil, fmt.Errorf("member %q not found", token)
+}
+
+// copyDocument returns a deep copy of a generic JSON value
+func copyDocument(doc interface{}) interface{} {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			c[k] = copyDocument(child)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, child := range v {
+			c[i] = copyDocument(child)
+		}
+		return c
+	}
+	return doc
+}
+
+// jsonEqual compares generic JSON values, with numbers compared by value as required by the test operation
+func jsonEqual(a interface{}, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, errA := a.Float64()
+		bf, errB := b.Float64()
+		return errA == nil && errB == nil && af == bf
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			bv, ok := b[k]
+			if !ok || !jsonEqual(v, bv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
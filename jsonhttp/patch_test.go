@@ -0,0 +1,294 @@
+package jsonhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type PatchModel struct {
+	ID           string `json:"id"`
+	PasswordHash string `json:"-"`
+}
+
+type patchTimestamps struct {
+	Created  string `json:"created"`
+	Internal string `json:"-"`
+}
+
+type patchAudit struct {
+	UpdatedBy string `json:"-"`
+	Revision  int    `json:"revision"`
+}
+
+type patchProfile struct {
+	Bio    string `json:"bio"`
+	Secret string `json:"-"`
+}
+
+type patchUser struct {
+	PatchModel
+	patchTimestamps
+	*patchAudit
+	Name    string            `json:"name" validate:"required"`
+	Email   string            `json:"email,omitempty"`
+	Profile *patchProfile     `json:"profile,omitempty"`
+	Address patchProfile      `json:"address"`
+	Tags    []string          `json:"tags,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	token   string
+}
+
+func newPatchUser() patchUser {
+	return patchUser{
+		PatchModel:      PatchModel{ID: "1", PasswordHash: "hash"},
+		patchTimestamps: patchTimestamps{Created: "today", Internal: "internal"},
+		patchAudit:      &patchAudit{UpdatedBy: "admin", Revision: 1},
+		Name:            "bob",
+		Email:           "bob@example.com",
+		Profile:         &patchProfile{Bio: "hi", Secret: "profile secret"},
+		Address:         patchProfile{Bio: "home", Secret: "address secret"},
+		Tags:            []string{"a", "b"},
+		Labels:          map[string]string{"team": "core", "tier": "1"},
+		token:           "token",
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		patch  string
+		modify func(u *patchUser)
+	}{
+		{"top-level member", `{"name":"alice"}`, func(u *patchUser) { u.Name = "alice" }},
+		{"null", `{"email":null}`, func(u *patchUser) { u.Email = "" }},
+		{"promoted member", `{"id":"2","revision":2}`, func(u *patchUser) { u.ID = "2"; u.Revision = 2 }},
+		{"promoted null", `{"id":null}`, func(u *patchUser) { u.ID = "" }},
+		{"unexported embedded", `{"created":"yesterday"}`, func(u *patchUser) { u.Created = "yesterday" }},
+		{"nested struct", `{"address":{"bio":"work"}}`, func(u *patchUser) { u.Address.Bio = "work" }},
+		{"nested pointer", `{"profile":{"bio":"hello"}}`, func(u *patchUser) { u.Profile = &patchProfile{Bio: "hello", Secret: "profile secret"} }},
+		{"nested pointer null", `{"profile":null}`, func(u *patchUser) { u.Profile = nil }},
+		{"slice", `{"tags":["c"]}`, func(u *patchUser) { u.Tags = []string{"c"} }},
+		{"map member", `{"labels":{"tier":null,"env":"prod"}}`, func(u *patchUser) { u.Labels = map[string]string{"team": "core", "env": "prod"} }},
+	}
+
+	for _, tt := range tests {
+		u := newPatchUser()
+		original := newPatchUser()
+		profile, labels := u.Profile, u.Labels
+
+		if err := ApplyMergePatch(&u, []byte(tt.patch)); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		want := newPatchUser()
+		tt.modify(&want)
+		if !reflect.DeepEqual(u, want) {
+			t.Errorf("%s: patched value should be %+v, got %+v", tt.name, want, u)
+		}
+		if !reflect.DeepEqual(*profile, *original.Profile) || !reflect.DeepEqual(labels, original.Labels) {
+			t.Errorf("%s: values shared with the original should not be modified, got %+v %v", tt.name, *profile, labels)
+		}
+	}
+}
+
+func TestApplyMergePatchCase(t *testing.T) {
+	tests := []struct {
+		name   string
+		patch  string
+		modify func(u *patchUser)
+	}{
+		{"field", `{"Name":"alice"}`, func(u *patchUser) { u.Name = "alice" }},
+		{"null", `{"EMAIL":null}`, func(u *patchUser) { u.Email = "" }},
+		{"promoted field", `{"ID":"2"}`, func(u *patchUser) { u.ID = "2" }},
+		{"nested field", `{"Address":{"BIO":"work"}}`, func(u *patchUser) { u.Address.Bio = "work" }},
+		{"map keys are kept", `{"labels":{"Team":"infra"}}`, func(u *patchUser) { u.Labels["Team"] = "infra" }},
+	}
+
+	for _, tt := range tests {
+		u := newPatchUser()
+		if err := ApplyMergePatch(&u, []byte(tt.patch)); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		want := newPatchUser()
+		tt.modify(&want)
+		if !reflect.DeepEqual(u, want) {
+			t.Errorf("%s: patched value should be %+v, got %+v", tt.name, want, u)
+		}
+	}
+
+	for _, patch := range []string{`{"Name":"alice","name":"bob"}`, `{"NAME":"alice","Name":"bob"}`} {
+		u := newPatchUser()
+		if err := ApplyMergePatch(&u, []byte(patch)); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("Patch %s should fail with ErrInvalidPatch, got %v", patch, err)
+		}
+	}
+
+	// JSON Pointers are case-sensitive, so adding a member with another case would hide the update
+	u := newPatchUser()
+	if err := ApplyJSONPatch(&u, []byte(`[{"op":"add","path":"/Name","value":"alice"}]`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("Adding a member that differs from a field only in case should fail with ErrInvalidPatch, got %v", err)
+	}
+}
+
+func TestApplyPatchFailure(t *testing.T) {
+	u := newPatchUser()
+	if err := ApplyMergePatch(&u, []byte(`{"name":1}`)); err == nil {
+		t.Errorf("Patching a field with the wrong type should fail")
+	}
+	if err := ApplyJSONPatch(&u, []byte(`[{"op":"replace","path":"/tags/0","value":"x"},{"op":"remove","path":"/missing"}]`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("Removing a missing member should fail with ErrInvalidPatch, got %v", err)
+	}
+	if !reflect.DeepEqual(u, newPatchUser()) {
+		t.Errorf("The target should not be modified by failed patches, got %+v", u)
+	}
+	if err := ApplyMergePatch(u, []byte(`{}`)); err == nil {
+		t.Errorf("Patching a non-pointer should fail")
+	}
+}
+
+func TestApplyPatchNonStruct(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2}
+	if err := ApplyMergePatch(&m, []byte(`{"a":null,"c":3}`)); err != nil || !reflect.DeepEqual(m, map[string]int{"b": 2, "c": 3}) {
+		t.Errorf("Merge patch of a map should be map[b:2 c:3], got %v, %v", m, err)
+	}
+
+	s := []int{1, 2, 3}
+	if err := ApplyJSONPatch(&s, []byte(`[{"op":"remove","path":"/0"}]`)); err != nil || !reflect.DeepEqual(s, []int{2, 3}) {
+		t.Errorf("JSON Patch of a slice should be [2 3], got %v, %v", s, err)
+	}
+
+	n := 5
+	if err := ApplyMergePatch(&n, []byte(`null`)); err != nil || n != 0 {
+		t.Errorf("A null merge patch should zero the value, got %d, %v", n, err)
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		pointer string
+		want    []string
+		ok      bool
+	}{
+		{"", nil, true},
+		{"/", []string{""}, true},
+		{"/foo/0", []string{"foo", "0"}, true},
+		{"/a~1b", []string{"a/b"}, true},
+		{"/m~0n", []string{"m~n"}, true},
+		// ~01 is ~1 unescaped, not a slash
+		{"/~01", []string{"~1"}, true},
+		{"foo", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePointer(tt.pointer)
+		if (err == nil) != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Pointer %q should be %q (ok %v), got %q, %v", tt.pointer, tt.want, tt.ok, got, err)
+		}
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+		err   error
+	}{
+		// RFC 6902 Appendix A
+		{"A.1 add object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`, nil},
+		{"A.2 add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, nil},
+		{"A.3 remove object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, nil},
+		{"A.4 remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`, nil},
+		{"A.5 replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`, nil},
+		{"A.6 move", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, nil},
+		{"A.7 move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`, nil},
+		{"A.8 test", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`, nil},
+		{"A.9 failed test", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ``, ErrPatchTestFailed},
+		{"A.10 add nested member", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`, nil},
+		{"A.11 unrecognized elements", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`, nil},
+		{"A.12 add to missing target", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ``, ErrInvalidPatch},
+		{"A.14 escape ordering", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`, nil},
+		{"A.15 strings and numbers", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":"10"}]`, ``, ErrPatchTestFailed},
+		{"A.16 add array value", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`, nil},
+
+		{"escaped slash", `{"a/b":1}`, `[{"op":"replace","path":"/a~1b","value":2}]`, `{"a/b":2}`, nil},
+		{"- only for add", `{"foo":["bar"]}`, `[{"op":"remove","path":"/foo/-"}]`, ``, ErrInvalidPatch},
+		{"leading zero index", `{"foo":["bar","baz"]}`, `[{"op":"remove","path":"/foo/01"}]`, ``, ErrInvalidPatch},
+		{"index past the end", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":"x"}]`, ``, ErrInvalidPatch},
+		{"move into child", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, ``, ErrInvalidPatch},
+		{"move to itself", `{"a":1}`, `[{"op":"move","from":"/a","path":"/a"}]`, `{"a":1}`, nil},
+		{"number equality", `{"n":1}`, `[{"op":"test","path":"/n","value":1.0},{"op":"test","path":"/n","value":1e0}]`, `{"n":1}`, nil},
+		{"copy does not alias", `{"a":{"x":1}}`, `[{"op":"copy","from":"/a","path":"/b"},{"op":"add","path":"/b/y","value":2}]`, `{"a":{"x":1},"b":{"x":1,"y":2}}`, nil},
+		{"replace root", `{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`, nil},
+		{"remove root", `{"a":1}`, `[{"op":"remove","path":""}]`, ``, ErrInvalidPatch},
+		{"missing value", `{}`, `[{"op":"add","path":"/a"}]`, ``, ErrInvalidPatch},
+		{"null value", `{}`, `[{"op":"add","path":"/a","value":null}]`, `{"a":null}`, nil},
+		{"unknown op", `{}`, `[{"op":"merge","path":"/a","value":1}]`, ``, ErrInvalidPatch},
+		{"not an array", `{}`, `{"op":"add"}`, ``, ErrInvalidPatch},
+	}
+
+	for _, tt := range tests {
+		doc, err := decodeDocument([]byte(tt.doc))
+		if err != nil {
+			t.Fatalf("%s: invalid document: %s", tt.name, err)
+		}
+		got, err := applyJSONPatch(doc, []byte(tt.patch))
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: error should be %v, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		want, _ := decodeDocument([]byte(tt.want))
+		if !jsonEqual(got, want) {
+			t.Errorf("%s: patched document should be %s, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestDecodePatchAndCatchForAPI(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		message     string
+		userName    string
+	}{
+		{"merge patch", MergePatchContentType, `{"name":"alice"}`, http.StatusOK, "", "alice"},
+		{"plain JSON", "application/json", `{"name":"alice"}`, http.StatusOK, "", "alice"},
+		{"no content type", "", `{"name":"alice"}`, http.StatusOK, "", "alice"},
+		{"JSON Patch", JSONPatchContentType, `[{"op":"test","path":"/name","value":"bob"},{"op":"replace","path":"/name","value":"alice"}]`, http.StatusOK, "", "alice"},
+		{"unsupported media type", "text/plain", `{"name":"alice"}`, http.StatusUnsupportedMediaType, "unsupported_media_type", "bob"},
+		{"invalid patch", JSONPatchContentType, `[{"op":"remove","path":"/missing"}]`, http.StatusUnprocessableEntity, "invalid_patch", "bob"},
+		{"failed test", JSONPatchContentType, `[{"op":"test","path":"/name","value":"carol"}]`, http.StatusConflict, "patch_test_failed", "bob"},
+		{"wrong type", MergePatchContentType, `{"name":1}`, http.StatusBadRequest, "Invalid JSON", "bob"},
+		{"checked", MergePatchContentType, `{"name":null}`, http.StatusUnprocessableEntity, "validation_failed", ""},
+	}
+
+	for _, tt := range tests {
+		u := newPatchUser()
+		w := httptest.NewRecorder()
+		err := JSONDecodePatchAndCatchForAPI(w, newJSONRequest(tt.body, tt.contentType), &u)
+
+		if (err == nil) != (tt.status == http.StatusOK) {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if tt.status != http.StatusOK && (w.Code != tt.status || !strings.Contains(w.Body.String(), `"message":"`+tt.message+`"`)) {
+			t.Errorf("%s: should respond %d %s, got %d %s", tt.name, tt.status, tt.message, w.Code, w.Body.String())
+		}
+		if u.Name != tt.userName || u.PasswordHash != "hash" {
+			t.Errorf("%s: name should be %q and the password hash kept, got %+v", tt.name, tt.userName, u)
+		}
+	}
+}
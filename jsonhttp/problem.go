@@ -0,0 +1,123 @@
+package jsonhttp
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details
+const ProblemContentType = "application/problem+json"
+
+// ErrorFormat selects how the JSONError family of functions renders error responses. Handlers use ErrorFormatEnvelope unless they are wrapped with WithErrorFormat or ProblemDetails, which can also wrap a whole router
+type ErrorFormat int
+
+const (
+	// ErrorFormatEnvelope renders errors as an APIResponse
+	ErrorFormatEnvelope ErrorFormat = iota
+	// ErrorFormatProblem renders errors as RFC 9457 problem details
+	ErrorFormatProblem
+)
+
+// Problem contains the members of an RFC 9457 problem details response. Extensions are rendered as additional top-level members
+type Problem struct {
+	Type       string                 `json:"type,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Status     int                    `json:"status,omitempty"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON renders the problem with its extensions as top-level members. Extensions never override the standard members
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	standard := map[string]string{
+		"type":     p.Type,
+		"title":    p.Title,
+		"detail":   p.Detail,
+		"instance": p.Instance,
+	}
+	for k, v := range standard {
+		if v != "" {
+			members[k] = v
+		} else {
+			delete(members, k)
+		}
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	} else {
+		delete(members, "status")
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON decodes the standard members into their fields and any other members into Extensions
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type standard Problem
+	var std standard
+	if err := json.Unmarshal(data, &std); err != nil {
+		return err
+	}
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, k := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, k)
+	}
+	*p = Problem(std)
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+	return nil
+}
+
+// JSONProblem returns the provided Problem on the http response as application/problem+json. The status defaults to 500 and the title to the status text
+func JSONProblem(w http.ResponseWriter, p Problem) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	writeJSON(w, p, p.Status, ProblemContentType)
+}
+
+// ProblemDetails is a middleware that makes the JSONError family of functions render RFC 9457 problem details for the wrapped handler. See httpmiddleware.Use for stacking
+func ProblemDetails(handler http.Handler) http.HandlerFunc {
+	return WithErrorFormat(ErrorFormatProblem)(handler)
+}
+
+// WithErrorFormat returns a middleware that selects the error format of the JSONError family of functions for the wrapped handler
+func WithErrorFormat(format ErrorFormat) func(http.Handler) http.HandlerFunc {
+	return func(handler http.Handler) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			sw := withSettings(w)
+			sw.settings.errorFormat = format
+			handler.ServeHTTP(sw, r)
+		}
+	}
+}
+
+// problemFromResponse converts an error APIResponse into problem details. The message becomes the detail and data and debug become extensions
+func problemFromResponse(resp APIResponse, statusCode int) Problem {
+	p := Problem{
+		Status: statusCode,
+		Title:  http.StatusText(statusCode),
+		Detail: resp.Message,
+	}
+	if resp.Data != nil || resp.Debug != "" {
+		p.Extensions = map[string]interface{}{}
+		if resp.Data != nil {
+			p.Extensions["data"] = resp.Data
+		}
+		if resp.Debug != "" {
+			p.Extensions["debug"] = resp.Debug
+		}
+	}
+	return p
+}
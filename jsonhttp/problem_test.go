@@ -0,0 +1,147 @@
+package jsonhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestProblemMarshalJSON(t *testing.T) {
+	p := Problem{
+		Type:   "https://example.com/probs/out-of-credit",
+		Title:  "You do not have enough credit.",
+		Status: http.StatusForbidden,
+		Detail: "Your current balance is 30, but that costs 50.",
+		Extensions: map[string]interface{}{
+			"balance": 30,
+			"title":   "extensions never override standard members",
+		},
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Error marshaling Problem: %s", err)
+	}
+	want := `{"balance":30,"detail":"Your current balance is 30, but that costs 50.","status":403,"title":"You do not have enough credit.","type":"https://example.com/probs/out-of-credit"}`
+	if string(b) != want {
+		t.Errorf("Marshaled Problem should be %s, got %s", want, b)
+	}
+
+	var decoded Problem
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Error unmarshaling Problem: %s", err)
+	}
+	p.Extensions = map[string]interface{}{"balance": float64(30)}
+	if !reflect.DeepEqual(decoded, p) {
+		t.Errorf("Round trip of Problem should be %#v, got %#v", p, decoded)
+	}
+
+	b, err = json.Marshal(Problem{})
+	if err != nil || string(b) != `{}` {
+		t.Errorf("Marshaled empty Problem should be {}, got %s, %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`{"status":404}`), &decoded); err != nil || decoded.Extensions != nil {
+		t.Errorf("Problem without extensions should have nil Extensions, got %v, %v", decoded.Extensions, err)
+	}
+}
+
+func TestJSONProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	JSONProblem(w, Problem{Detail: "boom"})
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Status should default to 500, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("Content-Type should be %s, got %s", ProblemContentType, ct)
+	}
+	want := `{"detail":"boom","status":500,"title":"Internal Server Error"}`
+	if w.Body.String() != want {
+		t.Errorf("Body should be %s, got %s", want, w.Body.String())
+	}
+}
+
+func TestProblemFromResponse(t *testing.T) {
+	tests := []struct {
+		resp APIResponse
+		want Problem
+	}{
+		{
+			APIResponse{Message: "not_found"},
+			Problem{Status: 404, Title: "Not Found", Detail: "not_found"},
+		},
+		{
+			APIResponse{Message: "validation_failed", Data: []int{1}, Debug: "details"},
+			Problem{Status: 404, Title: "Not Found", Detail: "validation_failed", Extensions: map[string]interface{}{
+				"data":  []int{1},
+				"debug": "details",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := problemFromResponse(tt.resp, http.StatusNotFound); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Problem from %#v should be %#v, got %#v", tt.resp, tt.want, got)
+		}
+	}
+}
+
+func TestErrorFormat(t *testing.T) {
+	notFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		JSONNotFoundError(w, "", "missing")
+	})
+
+	tests := []struct {
+		name        string
+		handler     http.Handler
+		contentType string
+		body        string
+	}{
+		{"default", notFound, "application/json", `{"message":"not_found","success":false,"data":null,"debug":"missing"}`},
+		{"ProblemDetails", ProblemDetails(notFound), ProblemContentType, `{"debug":"missing","detail":"not_found","status":404,"title":"Not Found"}`},
+		{"inner override", ProblemDetails(WithErrorFormat(ErrorFormatEnvelope)(notFound)), "application/json", `{"message":"not_found","success":false,"data":null,"debug":"missing"}`},
+		{"outer override", WithErrorFormat(ErrorFormatEnvelope)(ProblemDetails(notFound)), ProblemContentType, `{"debug":"missing","detail":"not_found","status":404,"title":"Not Found"}`},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		tt.handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: status should be 404, got %d", tt.name, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s: Content-Type should be %s, got %s", tt.name, tt.contentType, ct)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: body should be %s, got %s", tt.name, tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestSettingsFromWrappedWriter(t *testing.T) {
+	sw := withSettings(httptest.NewRecorder())
+	sw.settings.errorFormat = ErrorFormatProblem
+
+	// Writers of other middlewares that support Unwrap keep the settings reachable
+	wrapped := struct {
+		http.ResponseWriter
+		unwrapper
+	}{sw, unwrapper{sw}}
+	if f := errorFormatFor(wrapped); f != ErrorFormatProblem {
+		t.Errorf("Error format should be found through Unwrap, got %v", f)
+	}
+	if f := errorFormatFor(httptest.NewRecorder()); f != ErrorFormatEnvelope {
+		t.Errorf("Error format should default to the envelope, got %v", f)
+	}
+}
+
+type unwrapper struct {
+	w http.ResponseWriter
+}
+
+func (u unwrapper) Unwrap() http.ResponseWriter {
+	return u.w
+}
@@ -0,0 +1,74 @@
+package jsonhttp
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// handlerSettings holds the per-handler overrides of the package defaults set by the jsonhttp middlewares
+type handlerSettings struct {
+	errorFormat ErrorFormat
+	contentType string
+	indent      string
+	errorHook   func(err error)
+}
+
+// settingsWriter carries handler settings on the http.ResponseWriter, so the response functions that only receive the writer can honor them
+type settingsWriter struct {
+	http.ResponseWriter
+	settings handlerSettings
+}
+
+// Unwrap returns the wrapped http.ResponseWriter for http.ResponseController
+func (w *settingsWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush implements http.Flusher when the wrapped writer supports it
+func (w *settingsWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker by delegating to the wrapped writer. It returns http.ErrNotSupported if the wrapped writer can't be hijacked, e.g. for HTTP/2 requests, so callers must check the error rather than rely on the type assertion
+func (w *settingsWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Push implements http.Pusher by delegating to the wrapped writer. It returns http.ErrNotSupported if the wrapped writer doesn't support server push
+func (w *settingsWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// withSettings returns the settingsWriter of w, wrapping w in a new one if it has none. Settings of an outer middleware are copied so inner middlewares can override them
+func withSettings(w http.ResponseWriter) *settingsWriter {
+	return &settingsWriter{ResponseWriter: w, settings: settingsFrom(w)}
+}
+
+// settingsFrom returns the handler settings carried by w or any writer it wraps
+func settingsFrom(w http.ResponseWriter) handlerSettings {
+	for w != nil {
+		if sw, ok := w.(*settingsWriter); ok {
+			return sw.settings
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	return handlerSettings{}
+}
+
+// errorFormatFor returns the error format selected for w
+func errorFormatFor(w http.ResponseWriter) ErrorFormat {
+	return settingsFrom(w).errorFormat
+}
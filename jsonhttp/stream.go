@@ -0,0 +1,122 @@
+package jsonhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+)
+
+// NDJSONContentType is the media type of newline delimited JSON
+const NDJSONContentType = "application/x-ndjson"
+
+// streamFormat describes how the encoded elements of a stream are framed in the response body
+type streamFormat struct {
+	contentType string
+	open        string
+	separator   string
+	terminator  string
+	close       string
+}
+
+// JSONStream returns a successful APIResponse on the http response like JSONSuccess, encoding the elements of seq into the data array one at a time. Errors are reported to the error hook and returned
+func JSONStream[T any](w http.ResponseWriter, seq iter.Seq[T], message string) error {
+	if message == "" {
+		message = "ok"
+	}
+	msg, err := json.Marshal(message)
+	if err != nil {
+		JSONInternalError(w, "", "")
+		return err
+	}
+	return streamJSON(w, seq, streamFormat{
+		contentType: contentTypeFor(w),
+		open:        `{"message":` + string(msg) + `,"success":true,"data":[`,
+		separator:   ",",
+		close:       "]}",
+	})
+}
+
+// JSONStreamChan is JSONStream for elements received from ch until it is closed. The stream stops receiving on errors, so producers should also stop on the cancellation of the request context
+func JSONStreamChan[T any](w http.ResponseWriter, ch <-chan T, message string) error {
+	return JSONStream(w, chanSeq(ch), message)
+}
+
+// NDJSON returns the elements of seq on the http response as newline delimited JSON, one element per line without an APIResponse envelope. Errors are handled like in JSONStream
+func NDJSON[T any](w http.ResponseWriter, seq iter.Seq[T]) error {
+	return streamJSON(w, seq, streamFormat{
+		contentType: NDJSONContentType,
+		terminator:  "\n",
+	})
+}
+
+// NDJSONChan is NDJSON for elements received from ch until it is closed. The stream stops receiving on errors, so producers should also stop on the cancellation of the request context
+func NDJSONChan[T any](w http.ResponseWriter, ch <-chan T) error {
+	return NDJSON(w, chanSeq(ch))
+}
+
+// streamJSON writes the elements of seq in the provided format. The headers are only committed once the first element is encoded, so an early failure can still be reported with a proper status code
+func streamJSON[T any](w http.ResponseWriter, seq iter.Seq[T], format streamFormat) error {
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", format.contentType)
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, format.open)
+		return err
+	}
+
+	var err error
+	for v := range seq {
+		var dj []byte
+		dj, err = json.Marshal(v)
+		if err != nil {
+			break
+		}
+		if !started {
+			err = start()
+		} else {
+			_, err = io.WriteString(w, format.separator)
+		}
+		if err == nil {
+			_, err = w.Write(dj)
+		}
+		if err == nil && format.terminator != "" {
+			_, err = io.WriteString(w, format.terminator)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err != nil {
+		// The handler usually can't do more than log the error, so it is reported like a failure of JSONWriter
+		reportError(w, fmt.Errorf("jsonhttp: stream response: %w", err))
+		if !started {
+			JSONInternalError(w, "", "")
+		}
+		return err
+	}
+
+	if !started {
+		err = start()
+	}
+	if err == nil {
+		_, err = io.WriteString(w, format.close)
+	}
+	if err != nil {
+		reportError(w, fmt.Errorf("jsonhttp: stream response: %w", err))
+	}
+	return err
+}
+
+// chanSeq returns a sequence of the values received from ch until it is closed
+func chanSeq[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
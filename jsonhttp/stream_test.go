@@ -0,0 +1,131 @@
+package jsonhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+type streamItem struct {
+	ID int `json:"id"`
+}
+
+func TestJSONStream(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []streamItem
+		body  string
+	}{
+		{"items", []streamItem{{1}, {2}}, `{"message":"ok","success":true,"data":[{"id":1},{"id":2}]}`},
+		{"empty", nil, `{"message":"ok","success":true,"data":[]}`},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		if err := JSONStream(w, slices.Values(tt.items), ""); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("%s: should respond 200 application/json, got %d %s", tt.name, w.Code, w.Header().Get("Content-Type"))
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: body should be %s, got %s", tt.name, tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []streamItem
+		body  string
+	}{
+		{"items", []streamItem{{1}, {2}}, "{\"id\":1}\n{\"id\":2}\n"},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		ch := make(chan streamItem, len(tt.items))
+		for _, item := range tt.items {
+			ch <- item
+		}
+		close(ch)
+
+		w := httptest.NewRecorder()
+		if err := NDJSONChan(w, ch); err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != NDJSONContentType {
+			t.Errorf("%s: should respond 200 %s, got %d %s", tt.name, NDJSONContentType, w.Code, w.Header().Get("Content-Type"))
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: body should be %q, got %q", tt.name, tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestStreamEncodeFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		items  []interface{}
+		status int
+		body   string
+	}{
+		{"first element", []interface{}{make(chan int), 2}, http.StatusInternalServerError, `{"message":"error","success":false,"data":null}`},
+		{"mid-stream", []interface{}{1, make(chan int), 3}, http.StatusOK, `{"message":"ok","success":true,"data":[1`},
+	}
+
+	for _, tt := range tests {
+		var reported []error
+		h := WithErrorHook(func(err error) { reported = append(reported, err) })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := JSONStream(w, slices.Values(tt.items), ""); err == nil {
+				t.Errorf("%s: JSONStream should return the encoding error", tt.name)
+			}
+		}))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("%s: should respond %d %s, got %d %s", tt.name, tt.status, tt.body, w.Code, w.Body.String())
+		}
+		if len(reported) != 1 {
+			t.Errorf("%s: the error should be reported to the hook once, got %v", tt.name, reported)
+		}
+	}
+}
+
+// failingWriter fails every write after the first n
+type failingWriter struct {
+	*httptest.ResponseRecorder
+	n int
+}
+
+var errWriteFailed = errors.New("connection reset")
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if w.n == 0 {
+		return 0, errWriteFailed
+	}
+	w.n--
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestStreamWriteFailure(t *testing.T) {
+	var reported error
+	h := WithErrorHook(func(err error) { reported = err })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := NDJSON(w, slices.Values([]int{1, 2, 3})); !errors.Is(err, errWriteFailed) {
+			t.Errorf("NDJSON should return the write error, got %v", err)
+		}
+	}))
+
+	w := &failingWriter{ResponseRecorder: httptest.NewRecorder(), n: 3}
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if !errors.Is(reported, errWriteFailed) {
+		t.Errorf("The write error should be reported to the hook, got %v", reported)
+	}
+	if w.Body.String() != "1\n" {
+		t.Errorf("The stream should stop at the failed write, got %q", w.Body.String())
+	}
+}
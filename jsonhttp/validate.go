@@ -0,0 +1,350 @@
+package jsonhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Validate checks the `validate` struct tags of v, descending into nested structs, pointers, slices, arrays and maps, and returns an error for every field that breaks a rule. Field paths use the JSON field names. The supported rules are:
+//
+//	required    the value is not the zero value (non-empty for strings, slices and maps, non-nil for pointers)
+//	min=N       strings have at least N characters, slices and maps at least N items, numbers are at least N
+//	max=N       strings have at most N characters, slices and maps at most N items, numbers are at most N
+//	email       the string is a plain email address
+//	oneof=a b   the string or number is one of the space-separated values
+//
+// Rules are comma-separated, e.g. `validate:"required,min=1,max=100"`. Unknown rules and rules that don't apply to the field are ignored
+func Validate(v interface{}) ValidationErrors {
+	var errs ValidationErrors
+	validateValue(reflect.ValueOf(v), "", &errs)
+	return errs
+}
+
+// CheckValidateTags reports the `validate` rules of the type of v that Validate ignores because they don't apply to the field. Unknown rules are not reported
+func CheckValidateTags(v interface{}) error {
+	var errs []error
+	checkValidateType(reflect.TypeOf(v), map[reflect.Type]bool{}, &errs)
+	return errors.Join(errs...)
+}
+
+// validationRule is a parsed rule of a `validate` tag
+type validationRule struct {
+	name    string
+	param   string
+	bound   float64
+	options []string
+}
+
+// validatedField is a struct field that is validated or descended into
+type validatedField struct {
+	index   int
+	name    string // JSON name of the field, "" for embedded structs whose fields are promoted
+	rules   []validationRule
+	descend bool
+}
+
+// validatedStruct holds the parsed `validate` tags of a struct type
+type validatedStruct struct {
+	fields []validatedField
+	errs   []error
+}
+
+// validatedStructs caches the validatedStruct of every struct type seen by Validate, so tags are parsed once per type
+var validatedStructs sync.Map
+
+// validatedStructFor returns the parsed `validate` tags of the struct type t
+func validatedStructFor(t reflect.Type) *validatedStruct {
+	if cached, ok := validatedStructs.Load(t); ok {
+		return cached.(*validatedStruct)
+	}
+
+	vs := &validatedStruct{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		name, ok := jsonFieldName(f)
+		if !ok {
+			continue
+		}
+		field := validatedField{index: i, name: name, descend: mayContainStructs(f.Type)}
+		if tag := f.Tag.Get("validate"); tag != "" && tag != "-" {
+			field.rules = parseValidationRules(t, f, tag, &vs.errs)
+		}
+		if len(field.rules) > 0 || field.descend {
+			vs.fields = append(vs.fields, field)
+		}
+	}
+
+	cached, _ := validatedStructs.LoadOrStore(t, vs)
+	return cached.(*validatedStruct)
+}
+
+// parseValidationRules parses the known rules of the tag of field f of struct type t. Rules that cannot be applied to the field are left out and reported in errs
+func parseValidationRules(t reflect.Type, f reflect.StructField, tag string, errs *[]error) []validationRule {
+	ft := f.Type
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	kind := ft.Kind()
+
+	var rules []validationRule
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		r := validationRule{name: name, param: param}
+
+		var ok bool
+		switch name {
+		case "required":
+			ok = true
+		case "min", "max":
+			var err error
+			if r.bound, err = strconv.ParseFloat(param, 64); err != nil {
+				*errs = append(*errs, fmt.Errorf("jsonhttp: invalid parameter %q for validation rule %s on field %s.%s", param, name, t, f.Name))
+				continue
+			}
+			ok = isLengthKind(kind) || isNumberKind(kind)
+		case "email":
+			ok = kind == reflect.String
+		case "oneof":
+			r.options = strings.Fields(param)
+			ok = kind == reflect.String || isNumberKind(kind)
+		default:
+			// rules of other validators
+			continue
+		}
+
+		if !ok && kind != reflect.Interface {
+			*errs = append(*errs, fmt.Errorf("jsonhttp: validation rule %s on %s field %s.%s", name, kind, t, f.Name))
+			continue
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// checkValidateType collects the errors of the validatedStruct of t and of the struct types it contains
+func checkValidateType(t reflect.Type, seen map[reflect.Type]bool, errs *[]error) {
+	if t == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	vs := validatedStructFor(t)
+	*errs = append(*errs, vs.errs...)
+	for _, f := range vs.fields {
+		checkValidateType(t.Field(f.index).Type, seen, errs)
+	}
+}
+
+// mayContainStructs reports whether values of type t can contain structs, so Validate descends into them. Slices such as []byte or []string are not walked
+func mayContainStructs(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return mayContainStructs(t.Elem())
+	}
+	return false
+}
+
+// validateValue descends into v, validating the tagged fields of every struct it contains
+func validateValue(v reflect.Value, path string, errs *ValidationErrors) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range validatedStructFor(v.Type()).fields {
+			fieldPath := joinFieldPath(path, f.name)
+			if f.name == "" {
+				// embedded struct without a JSON name: its fields are promoted like in encoding/json
+				fieldPath = path
+			}
+			fv := v.Field(f.index)
+			validateField(fv, fieldPath, f.rules, errs)
+			if f.descend {
+				validateValue(fv, fieldPath, errs)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if !mayContainStructs(v.Type().Elem()) {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), joinFieldPath(path, strconv.Itoa(i)), errs)
+		}
+	case reflect.Map:
+		if !mayContainStructs(v.Type().Elem()) {
+			return
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			validateValue(v.MapIndex(k), joinFieldPath(path, fmt.Sprint(k)), errs)
+		}
+	}
+}
+
+// jsonFieldName returns the JSON name of the struct field, or "" for embedded structs whose fields are promoted. ok is false if the field is not encoded
+func jsonFieldName(f reflect.StructField) (name string, ok bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	if name != "" {
+		return name, true
+	}
+	if f.Anonymous {
+		t := f.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return "", true
+		}
+		if !f.IsExported() {
+			return "", false
+		}
+	}
+	return f.Name, true
+}
+
+func joinFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func isLengthKind(kind reflect.Kind) bool {
+	return kind == reflect.String || kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return reflect.Int <= kind && kind <= reflect.Float64
+}
+
+// validateField checks the parsed rules of a single field. Rules that do not apply to the kind of an interface field's value are skipped
+func validateField(v reflect.Value, path string, rules []validationRule, errs *ValidationErrors) {
+	for _, rule := range rules {
+		if rule.name == "required" {
+			if v.IsZero() {
+				errs.Add(path, "required", "is required")
+				return
+			}
+			continue
+		}
+
+		fv := v
+		for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				return
+			}
+			fv = fv.Elem()
+		}
+
+		switch rule.name {
+		case "min", "max":
+			validateBound(fv, path, rule, errs)
+		case "email":
+			if fv.Kind() != reflect.String || fv.String() == "" {
+				continue
+			}
+			s := fv.String()
+			if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+				errs.Add(path, "email", "must be a valid email address")
+			}
+		case "oneof":
+			if fv.IsZero() || !(fv.Kind() == reflect.String || isNumberKind(fv.Kind())) {
+				continue
+			}
+			value := fmt.Sprint(fv.Interface())
+			found := false
+			for _, option := range rule.options {
+				if option == value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				errs.Add(path, "oneof", "must be one of: "+strings.Join(rule.options, ", "))
+			}
+		}
+	}
+}
+
+// validateBound checks a min or max rule against the length of strings, slices and maps or the value of numbers
+func validateBound(v reflect.Value, path string, rule validationRule, errs *ValidationErrors) {
+	var value float64
+	unit := ""
+	switch v.Kind() {
+	case reflect.String:
+		value = float64(utf8.RuneCountInString(v.String()))
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		value = float64(v.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+	default:
+		return
+	}
+
+	if rule.name == "min" && value < rule.bound {
+		if unit == "" {
+			errs.Add(path, "min", "must be at least "+rule.param)
+		} else {
+			errs.Add(path, "min", "must have at least "+rule.param+unit)
+		}
+	}
+	if rule.name == "max" && value > rule.bound {
+		if unit == "" {
+			errs.Add(path, "max", "must be at most "+rule.param)
+		} else {
+			errs.Add(path, "max", "must have at most "+rule.param+unit)
+		}
+	}
+}
+
+// mergeValidation combines the errors from the `validate` tags with the error returned by Parameters(). An *APIError from Parameters() is returned as is, other errors are added to the field errors
+func mergeValidation(tagErrs ValidationErrors, paramErr error) error {
+	if len(tagErrs) == 0 {
+		return paramErr
+	}
+	if paramErr == nil {
+		return tagErrs
+	}
+	var apiErr *APIError
+	if errors.As(paramErr, &apiErr) {
+		return paramErr
+	}
+	var paramValidationErrs ValidationErrors
+	if errors.As(paramErr, &paramValidationErrs) {
+		return append(tagErrs, paramValidationErrs...)
+	}
+	return append(tagErrs, FieldError{Code: "invalid", Message: paramErr.Error()})
+}
@@ -0,0 +1,181 @@
+package jsonhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type validateAddress struct {
+	Zip string `json:"zip" validate:"required,min=5,max=5"`
+}
+
+type validateBase struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type validatePayload struct {
+	validateBase
+	Name     string                     `json:"name" validate:"required,max=5"`
+	Email    string                     `json:"email,omitempty" validate:"email"`
+	Role     string                     `json:"role" validate:"oneof=admin user"`
+	Age      *int                       `json:"age" validate:"min=18"`
+	Tags     []string                   `json:"tags" validate:"max=2"`
+	Address  *validateAddress           `json:"address"`
+	Others   []validateAddress          `json:"others"`
+	ByName   map[string]validateAddress `json:"by_name"`
+	Token    string                     `json:"token" validate:"required,uuid4"`
+	Raw      []byte                     `json:"raw"`
+	Internal string                     `json:"-" validate:"required"`
+}
+
+func validPayload() validatePayload {
+	return validatePayload{
+		validateBase: validateBase{ID: "1"},
+		Name:         "alice",
+		Role:         "admin",
+		Token:        "not a uuid, uuid4 is another validator's rule",
+		Raw:          make([]byte, 1<<16),
+	}
+}
+
+func TestValidate(t *testing.T) {
+	age := 17
+
+	tests := []struct {
+		name   string
+		modify func(p *validatePayload)
+		want   ValidationErrors
+	}{
+		{"valid", func(p *validatePayload) {}, nil},
+		{"required", func(p *validatePayload) { p.Name = "" }, ValidationErrors{{Field: "name", Code: "required", Message: "is required"}}},
+		{"embedded", func(p *validatePayload) { p.ID = "" }, ValidationErrors{{Field: "id", Code: "required", Message: "is required"}}},
+		{"max characters", func(p *validatePayload) { p.Name = "élodie" }, ValidationErrors{{Field: "name", Code: "max", Message: "must have at most 5 characters"}}},
+		{"max runes", func(p *validatePayload) { p.Name = "éléa" }, nil},
+		{"email", func(p *validatePayload) { p.Email = "Alice <alice@example.com>" }, ValidationErrors{{Field: "email", Code: "email", Message: "must be a valid email address"}}},
+		{"valid email", func(p *validatePayload) { p.Email = "alice@example.com" }, nil},
+		{"oneof", func(p *validatePayload) { p.Role = "root" }, ValidationErrors{{Field: "role", Code: "oneof", Message: "must be one of: admin, user"}}},
+		{"pointer min", func(p *validatePayload) { p.Age = &age }, ValidationErrors{{Field: "age", Code: "min", Message: "must be at least 18"}}},
+		{"max items", func(p *validatePayload) { p.Tags = []string{"a", "b", "c"} }, ValidationErrors{{Field: "tags", Code: "max", Message: "must have at most 2 items"}}},
+		{"nested pointer", func(p *validatePayload) { p.Address = &validateAddress{Zip: "123"} }, ValidationErrors{{Field: "address.zip", Code: "min", Message: "must have at least 5 characters"}}},
+		{"slice", func(p *validatePayload) { p.Others = []validateAddress{{Zip: "12345"}, {}} }, ValidationErrors{{Field: "others.1.zip", Code: "required", Message: "is required"}}},
+		{"map", func(p *validatePayload) { p.ByName = map[string]validateAddress{"b": {}, "a": {Zip: "1"}} }, ValidationErrors{
+			{Field: "by_name.a.zip", Code: "min", Message: "must have at least 5 characters"},
+			{Field: "by_name.b.zip", Code: "required", Message: "is required"},
+		}},
+		{"unknown rule after failure", func(p *validatePayload) { p.Token = "" }, ValidationErrors{{Field: "token", Code: "required", Message: "is required"}}},
+	}
+
+	for _, tt := range tests {
+		p := validPayload()
+		tt.modify(&p)
+		if got := Validate(&p); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: errors should be %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestValidateUntagged(t *testing.T) {
+	values := []interface{}{nil, 42, "x", []byte("x"), &struct{ Name string }{}, map[string]interface{}{"a": &validateAddress{}}}
+	want := []int{0, 0, 0, 0, 0, 1}
+	for i, v := range values {
+		if got := Validate(v); len(got) != want[i] {
+			t.Errorf("Validate(%#v) should return %d errors, got %v", v, want[i], got)
+		}
+	}
+}
+
+type invalidTags struct {
+	Count  int `json:"count" validate:"min=one"`
+	Mail   int `json:"mail" validate:"email"`
+	Nested []struct {
+		Flag bool `json:"flag" validate:"max=1"`
+	} `json:"nested"`
+	Other string `json:"other" validate:"required,uuid4,len=36"`
+}
+
+func TestCheckValidateTags(t *testing.T) {
+	err := CheckValidateTags(&invalidTags{})
+	if err == nil {
+		t.Fatalf("CheckValidateTags should report invalid rules")
+	}
+	for _, want := range []string{`invalid parameter "one" for validation rule min on field jsonhttp.invalidTags.Count`, "rule email on int field jsonhttp.invalidTags.Mail", "rule max on bool field"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckValidateTags should report %s, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "uuid4") || strings.Contains(err.Error(), "len") {
+		t.Errorf("CheckValidateTags should not report unknown rules, got %v", err)
+	}
+
+	if err := CheckValidateTags(validatePayload{}); err != nil {
+		t.Errorf("CheckValidateTags should accept valid tags, got %v", err)
+	}
+
+	// Invalid rules are skipped while handling requests
+	if errs := Validate(&invalidTags{Mail: 1, Count: -1, Other: "x"}); errs != nil {
+		t.Errorf("Invalid rules should be ignored by Validate, got %v", errs)
+	}
+}
+
+func TestHandleInvalidTags(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("HandleWith should panic on invalid validate rules")
+		}
+	}()
+	Handle(func(ctx context.Context, req invalidTags) (struct{}, error) {
+		return struct{}{}, nil
+	})
+}
+
+type validateCheckable struct {
+	Name  string `json:"name" validate:"required"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+func (c *validateCheckable) Parameters() error {
+	if c.End < c.Start {
+		return errors.New("end must be after start")
+	}
+	return nil
+}
+
+func TestDecodeValidateTags(t *testing.T) {
+	w := httptest.NewRecorder()
+	JSONDecodeAndCatchForAPI(w, newJSONRequest(`{"start":2,"end":1}`, ""), &validateCheckable{})
+
+	want := `{"message":"validation_failed","success":false,"data":[{"field":"name","code":"required","message":"is required"},{"field":"","code":"invalid","message":"end must be after start"}]}`
+	if w.Code != http.StatusUnprocessableEntity || w.Body.String() != want {
+		t.Errorf("Tag and Parameters errors should be merged into %s, got %d %s", want, w.Code, w.Body.String())
+	}
+}
+
+func TestMergeValidation(t *testing.T) {
+	tagErrs := ValidationErrors{{Field: "name", Code: "required", Message: "is required"}}
+	forbidden := ForbiddenError("", "")
+
+	tests := []struct {
+		name     string
+		tagErrs  ValidationErrors
+		paramErr error
+		want     error
+	}{
+		{"none", nil, nil, nil},
+		{"tags only", tagErrs, nil, tagErrs},
+		{"parameters only", nil, forbidden, forbidden},
+		{"APIError wins", tagErrs, forbidden, forbidden},
+		{"ValidationErrors appended", tagErrs, ValidationErrors{{Field: "age", Code: "min", Message: "too young"}}, ValidationErrors{tagErrs[0], {Field: "age", Code: "min", Message: "too young"}}},
+	}
+
+	for _, tt := range tests {
+		if got := mergeValidation(tt.tagErrs, tt.paramErr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: merged error should be %#v, got %#v", tt.name, tt.want, got)
+		}
+	}
+}
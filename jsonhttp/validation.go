@@ -0,0 +1,85 @@
+package jsonhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ValidationStatusCode is the status code of responses for ValidationErrors returned by CheckableRequest.Parameters(). Parameters() can return a BadRequestError with the ValidationErrors as data to respond 400 instead
+const ValidationStatusCode = http.StatusUnprocessableEntity
+
+// FieldError describes why the value of a single request field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects field-level errors. CheckableRequest.Parameters() can return them to have the decode functions render each field error in the `data` of the response
+type ValidationErrors []FieldError
+
+// Add appends a field error for the field path (e.g. "address.zip" or "items.2.id", matching the paths reported by encoding/json)
+func (v *ValidationErrors) Add(field string, code string, message string) {
+	*v = append(*v, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err returns the ValidationErrors as an error, or nil if there are none. Return it from Parameters() so an empty list is not mistaken for an error
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Error returns the field errors joined into a single message
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, fe := range v {
+		if fe.Field == "" {
+			msgs = append(msgs, fe.Message)
+			continue
+		}
+		msgs = append(msgs, fe.Field+": "+fe.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// parametersError converts an error returned by CheckableRequest.Parameters() into the APIError sent to the client
+func parametersError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	var validationErrs ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return NewAPIError(ValidationStatusCode, "validation_failed", "").WithData(validationErrs).Wrap(err)
+	}
+	return BadRequestError("", err.Error()).Wrap(err)
+}
+
+// decodeFieldErrors translates encoding/json errors about a specific field into ValidationErrors, or returns nil if err is not about a field
+func decodeFieldErrors(err error) ValidationErrors {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return ValidationErrors{{
+			Field:   typeErr.Field,
+			Code:    "invalid_type",
+			Message: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value),
+		}}
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, err := strconv.Unquote(field); err == nil {
+			field = unquoted
+		}
+		return ValidationErrors{{
+			Field:   field,
+			Code:    "unknown_field",
+			Message: "unknown field",
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package jsonhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fieldErrorsPayload struct {
+	Name    string `json:"name"`
+	Address struct {
+		Zip int `json:"zip"`
+	} `json:"address"`
+	Items []struct {
+		ID int `json:"id"`
+	} `json:"items"`
+}
+
+func TestDecodeFieldErrors(t *testing.T) {
+	tests := []struct {
+		body string
+		want ValidationErrors
+	}{
+		{`{"name":1}`, ValidationErrors{{Field: "name", Code: "invalid_type", Message: "expected string, got number"}}},
+		{`{"address":{"zip":"x"}}`, ValidationErrors{{Field: "address.zip", Code: "invalid_type", Message: "expected int, got string"}}},
+		{`{"items":[{"id":1},{"id":true}]}`, ValidationErrors{{Field: "items.1.id", Code: "invalid_type", Message: "expected int, got bool"}}},
+		{`{"nickname":"x"}`, ValidationErrors{{Field: "nickname", Code: "unknown_field", Message: "unknown field"}}},
+		{`{"name":`, nil},
+	}
+
+	for _, tt := range tests {
+		var out fieldErrorsPayload
+		decoder := json.NewDecoder(strings.NewReader(tt.body))
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&out)
+		if err == nil {
+			t.Fatalf("Decoding %s should fail", tt.body)
+		}
+		if got := decodeFieldErrors(err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Field errors of %s should be %v, got %v (from %q)", tt.body, tt.want, got, err)
+		}
+	}
+}
+
+// TestUnknownFieldErrorFormat pins the encoding/json message decodeFieldErrors relies on, which has no typed error
+func TestUnknownFieldErrorFormat(t *testing.T) {
+	decoder := json.NewDecoder(strings.NewReader(`{"nickname":"x"}`))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&fieldErrorsPayload{})
+	if err == nil || err.Error() != `json: unknown field "nickname"` {
+		t.Errorf(`encoding/json should report unknown fields as json: unknown field "nickname", got %v`, err)
+	}
+}
+
+func TestParametersError(t *testing.T) {
+	validationErrs := ValidationErrors{{Field: "name", Code: "required", Message: "is required"}}
+	forbidden := ForbiddenError("", "")
+
+	tests := []struct {
+		err     error
+		status  int
+		message string
+		data    interface{}
+	}{
+		{validationErrs, ValidationStatusCode, "validation_failed", validationErrs},
+		{fmt.Errorf("checking: %w", validationErrs), ValidationStatusCode, "validation_failed", validationErrs},
+		{forbidden, http.StatusForbidden, "forbidden", nil},
+		{errors.New("name is required"), http.StatusBadRequest, "bad_request", nil},
+	}
+
+	for _, tt := range tests {
+		apiErr := parametersError(tt.err)
+		if apiErr.status() != tt.status || apiErr.message() != tt.message || !reflect.DeepEqual(apiErr.Data, tt.data) {
+			t.Errorf("Error %v should become %d %s %v, got %d %s %v", tt.err, tt.status, tt.message, tt.data, apiErr.status(), apiErr.message(), apiErr.Data)
+		}
+	}
+}
+
+type validationPayload struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func (p *validationPayload) Parameters() error {
+	var errs ValidationErrors
+	if p.Name == "" {
+		errs.Add("name", "required", "is required")
+	}
+	if p.Age < 0 {
+		errs.Add("age", "min", "must be at least 0")
+	}
+	return errs.Err()
+}
+
+func TestValidationErrorsResponse(t *testing.T) {
+	tests := []struct {
+		body   string
+		status int
+		resp   string
+	}{
+		{`{"name":"a"}`, http.StatusOK, ``},
+		{`{"age":-1}`, ValidationStatusCode, `{"message":"validation_failed","success":false,"data":[{"field":"name","code":"required","message":"is required"},{"field":"age","code":"min","message":"must be at least 0"}]}`},
+		{`{"age":"x"}`, http.StatusBadRequest, `{"message":"Invalid JSON","success":false,"data":[{"field":"age","code":"invalid_type","message":"expected int, got string"}],"debug":`},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		JSONDecodeAndCatchForAPI(w, newJSONRequest(tt.body, ""), &validationPayload{})
+		// The debug message is encoding/json's, so only the body before it is compared
+		if w.Code != tt.status || !strings.HasPrefix(w.Body.String(), tt.resp) {
+			t.Errorf("%s should respond %d %s, got %d %s", tt.body, tt.status, tt.resp, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestValidationErrorsErr(t *testing.T) {
+	var errs ValidationErrors
+	if errs.Err() != nil {
+		t.Errorf("Empty ValidationErrors should not be an error")
+	}
+	errs.Add("", "invalid", "dates overlap")
+	errs.Add("end", "min", "must be after start")
+	if want := "dates overlap; end: must be after start"; errs.Err().Error() != want {
+		t.Errorf("Error should be %q, got %q", want, errs.Err().Error())
+	}
+}
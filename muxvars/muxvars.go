@@ -2,6 +2,7 @@ package muxvars
 
 import (
 	"encoding/base64"
+	"github.com/exlinc/golang-utils/uuid"
 	"github.com/gorilla/mux"
 	"net/http"
 	"strconv"
@@ -68,3 +69,24 @@ func GetURLBase64EncodedStringVar(r *http.Request, key string) string {
 	}
 	return string(value)
 }
+
+// GetUUIDVar tries to get the var with the key and parse it into a UUID in any of the representations accepted by uuid.ParseAny
+func GetUUIDVar(r *http.Request, key string) uuid.UUID {
+	return GetUUIDVarWith(r, key, uuid.EncodingBase58)
+}
+
+// GetUUIDVarWith is like GetUUIDVar, but parses 22-character alphanumeric vars in the provided encoding like uuid.ParseAnyWith
+func GetUUIDVarWith(r *http.Request, key string, enc uuid.Encoding) uuid.UUID {
+	vars := mux.Vars(r)
+	if _, ok := vars[key]; !ok {
+		return uuid.Nil
+	}
+	if len(vars[key]) < 1 {
+		return uuid.Nil
+	}
+	value, err := uuid.ParseAnyWith(vars[key], enc)
+	if err != nil {
+		return uuid.Nil
+	}
+	return value
+}
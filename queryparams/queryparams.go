@@ -5,23 +5,29 @@ import (
 	"strconv"
 )
 
+// Names of the query parameters read by the GetLimitOffset functions
+const (
+	LimitParameter  = "limit"
+	OffsetParameter = "offset"
+)
+
 func GetLimitOffsetQueryParametersSentinel(r *http.Request, sentinel int64) (limit, offset int64) {
 	limit, offset = GetLimitOffsetQueryParametersDefaults(r)
-	if r.URL.Query().Get("limit") == "" {
+	if r.URL.Query().Get(LimitParameter) == "" {
 		limit = sentinel
 	}
-	if r.URL.Query().Get("offset") == "" {
+	if r.URL.Query().Get(OffsetParameter) == "" {
 		offset = sentinel
 	}
 	return
 }
 
 func GetLimitOffsetQueryParametersDefaults(r *http.Request) (limit, offset int64) {
-	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	offset, err := strconv.ParseInt(r.URL.Query().Get(OffsetParameter), 10, 64)
 	if err != nil || offset < 0 {
 		offset = 0
 	}
-	limit, err = strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+	limit, err = strconv.ParseInt(r.URL.Query().Get(LimitParameter), 10, 64)
 	if err != nil || limit < 0 {
 		limit = 10
 	}
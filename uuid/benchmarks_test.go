@@ -32,6 +32,13 @@ func BenchmarkFromStringWithBrackets(b *testing.B) {
 	}
 }
 
+func BenchmarkFromStringHyphenless(b *testing.B) {
+	s := "6ba7b8109dad11d180b400c04fd430c8"
+	for i := 0; i < b.N; i++ {
+		FromString(s)
+	}
+}
+
 func BenchmarkNewV1(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		NewV1()
@@ -62,6 +69,18 @@ func BenchmarkNewV5(b *testing.B) {
 	}
 }
 
+func BenchmarkNewV6(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NewV6()
+	}
+}
+
+func BenchmarkNewV7(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NewV7()
+	}
+}
+
 func BenchmarkMarshalBinary(b *testing.B) {
 	u := NewV4()
 	for i := 0; i < b.N; i++ {
@@ -100,3 +119,38 @@ func BenchmarkMarshalToString(b *testing.B) {
 		sink = u.String()
 	}
 }
+
+func BenchmarkNewV4Parallel(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			NewV4()
+		}
+	})
+}
+
+func BenchmarkNewV4PooledParallel(b *testing.B) {
+	g := NewGenerator(WithRandomPool(4096))
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			g.NewV4()
+		}
+	})
+}
+
+func BenchmarkFillParallel(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		// Each iteration takes one UUID from a batch refilled every 256 iterations.
+		batch := make([]UUID, 256)
+		i := len(batch)
+		for pb.Next() {
+			if i == len(batch) {
+				Fill(batch)
+				i = 0
+			}
+			sinkUUID = batch[i]
+			i++
+		}
+	})
+}
+
+var sinkUUID UUID
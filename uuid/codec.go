@@ -0,0 +1,225 @@
+package uuid
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// Encoding identifies a compact string encoding of UUID.
+type Encoding int
+
+// Compact UUID encodings.
+const (
+	EncodingBase58 Encoding = iota
+	EncodingBase62
+	EncodingCrockford
+	EncodingBase64URL
+)
+
+// Alphabets of compact encodings.
+const (
+	base58Alphabet    = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	base62Alphabet    = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// Encoded lengths of compact encodings.
+const (
+	base58Length    = 22
+	base62Length    = 22
+	crockfordLength = 26
+	base64URLLength = 22
+)
+
+// Decoding tables of compact encodings.
+var (
+	base58Decode    = decodeTable(base58Alphabet)
+	base62Decode    = decodeTable(base62Alphabet)
+	crockfordDecode = crockfordDecodeTable()
+)
+
+func decodeTable(alphabet string) (t [256]byte) {
+	for i := range t {
+		t[i] = 0xff
+	}
+	for i := 0; i < len(alphabet); i++ {
+		t[alphabet[i]] = byte(i)
+	}
+	return
+}
+
+// Crockford base32 decoding is case-insensitive and maps
+// the commonly confused letters I, L and O to digits.
+func crockfordDecodeTable() [256]byte {
+	t := decodeTable(crockfordAlphabet)
+	for i := 0; i < len(crockfordAlphabet); i++ {
+		c := crockfordAlphabet[i]
+		if c >= 'A' && c <= 'Z' {
+			t[c+'a'-'A'] = byte(i)
+		}
+	}
+	t['I'], t['i'], t['L'], t['l'] = 1, 1, 1, 1
+	t['O'], t['o'] = 0, 0
+	return t
+}
+
+// Encodes UUID as a fixed-width big-endian number in the base of the alphabet,
+// left-padded with the zero digit.
+func encodeBaseN(u UUID, alphabet string, width int) string {
+	base := uint32(len(alphabet))
+	buf := make([]byte, width)
+
+	for i := width - 1; i >= 0; i-- {
+		var rem uint32
+		for j := range u {
+			acc := rem<<8 | uint32(u[j])
+			u[j] = byte(acc / base)
+			rem = acc % base
+		}
+		buf[i] = alphabet[rem]
+	}
+
+	return string(buf)
+}
+
+// Checks that input has the fixed width of a compact encoding.
+func checkLength(input string, width int) error {
+	switch {
+	case len(input) < width:
+		return &ParseError{Input: input, Err: ErrTooShort}
+	case len(input) > width:
+		return &ParseError{Input: input, Err: ErrTooLong}
+	}
+	return nil
+}
+
+// Decodes a fixed-width big-endian number in the base of the decoding table.
+// It will return error on invalid characters or values exceeding 128 bits.
+func decodeBaseN(input string, table *[256]byte, base uint32, width int) (u UUID, err error) {
+	if err = checkLength(input, width); err != nil {
+		return
+	}
+
+	for i := 0; i < len(input); i++ {
+		digit := table[input[i]]
+		if digit == 0xff {
+			err = &ParseError{Input: input, Err: ErrInvalidFormat}
+			return
+		}
+
+		carry := uint32(digit)
+		for j := len(u) - 1; j >= 0; j-- {
+			acc := uint32(u[j])*base + carry
+			u[j] = byte(acc)
+			carry = acc >> 8
+		}
+		if carry != 0 {
+			err = &ParseError{Input: input, Err: ErrInvalidFormat}
+			return
+		}
+	}
+
+	return
+}
+
+// EncodeBase58 returns 22-character Base58 representation of UUID
+// using the Bitcoin alphabet.
+func (u UUID) EncodeBase58() string {
+	return encodeBaseN(u, base58Alphabet, base58Length)
+}
+
+// EncodeBase62 returns 22-character Base62 representation of UUID.
+func (u UUID) EncodeBase62() string {
+	return encodeBaseN(u, base62Alphabet, base62Length)
+}
+
+// EncodeCrockford returns 26-character Crockford base32 representation of UUID,
+// the same layout used by ULID.
+func (u UUID) EncodeCrockford() string {
+	return encodeBaseN(u, crockfordAlphabet, crockfordLength)
+}
+
+// EncodeBase64URL returns 22-character unpadded URL-safe Base64 representation of UUID.
+func (u UUID) EncodeBase64URL() string {
+	return base64.RawURLEncoding.EncodeToString(u[:])
+}
+
+// Encode returns representation of UUID in the provided compact encoding.
+func (u UUID) Encode(enc Encoding) string {
+	switch enc {
+	case EncodingBase62:
+		return u.EncodeBase62()
+	case EncodingCrockford:
+		return u.EncodeCrockford()
+	case EncodingBase64URL:
+		return u.EncodeBase64URL()
+	}
+	return u.EncodeBase58()
+}
+
+// FromBase58 returns UUID parsed from Base58 input.
+func FromBase58(input string) (UUID, error) {
+	return decodeBaseN(input, &base58Decode, 58, base58Length)
+}
+
+// FromBase62 returns UUID parsed from Base62 input.
+func FromBase62(input string) (UUID, error) {
+	return decodeBaseN(input, &base62Decode, 62, base62Length)
+}
+
+// FromCrockford returns UUID parsed from Crockford base32 input.
+// Decoding is case-insensitive and treats I and L as 1 and O as 0.
+func FromCrockford(input string) (UUID, error) {
+	return decodeBaseN(input, &crockfordDecode, 32, crockfordLength)
+}
+
+// FromBase64URL returns UUID parsed from unpadded URL-safe Base64 input.
+func FromBase64URL(input string) (u UUID, err error) {
+	if err = checkLength(input, base64URLLength); err != nil {
+		return
+	}
+	// Strict decoding rejects non-zero padding bits, so each UUID has exactly one encoding.
+	if _, decodeErr := base64.RawURLEncoding.Strict().Decode(u[:], []byte(input)); decodeErr != nil {
+		err = &ParseError{Input: input, Err: ErrInvalidFormat}
+	}
+	return
+}
+
+// Decode returns UUID parsed from input in the provided compact encoding.
+func Decode(input string, enc Encoding) (UUID, error) {
+	switch enc {
+	case EncodingBase62:
+		return FromBase62(input)
+	case EncodingCrockford:
+		return FromCrockford(input)
+	case EncodingBase64URL:
+		return FromBase64URL(input)
+	}
+	return FromBase58(input)
+}
+
+// ParseAny returns UUID parsed from input in any supported representation,
+// detecting the encoding from its length and alphabet:
+// 26 characters are Crockford base32, 22 characters containing '-' or '_'
+// are Base64URL, other 22-character input is Base58,
+// and everything else is handled by FromString.
+// Surrounding whitespace is ignored.
+func ParseAny(input string) (UUID, error) {
+	return ParseAnyWith(input, EncodingBase58)
+}
+
+// ParseAnyWith is like ParseAny, but decodes 22-character alphanumeric input,
+// which is ambiguous between Base58, Base62 and Base64URL, with enc.
+func ParseAnyWith(input string, enc Encoding) (UUID, error) {
+	input = strings.TrimSpace(input)
+	switch len(input) {
+	case crockfordLength:
+		return FromCrockford(input)
+	case base64URLLength:
+		if strings.ContainsAny(input, "-_") {
+			return FromBase64URL(input)
+		}
+		return Decode(input, enc)
+	}
+	return FromString(input)
+}
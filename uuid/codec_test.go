@@ -0,0 +1,137 @@
+package uuid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		enc  Encoding
+		want string
+	}{
+		{EncodingBase58, "EJ34kCVxxF9jHMKD4EgrAK"},
+		{EncodingBase62, "3H8pGALtipnCnHud4zBiky"},
+		{EncodingCrockford, "3BMYW117DD278R1D00R17X8C68"},
+		{EncodingBase64URL, "a6e4EJ2tEdGAtADAT9QwyA"},
+	}
+
+	for _, tt := range tests {
+		if s := NamespaceDNS.Encode(tt.enc); s != tt.want {
+			t.Errorf("Incorrect encoding %d of %s: %s, expected %s", tt.enc, NamespaceDNS, s, tt.want)
+		}
+
+		u, err := Decode(tt.want, tt.enc)
+		if err != nil {
+			t.Errorf("Error decoding %s with encoding %d: %s", tt.want, tt.enc, err)
+		}
+		if !Equal(u, NamespaceDNS) {
+			t.Errorf("Incorrect decoding of %s with encoding %d: %s", tt.want, tt.enc, u)
+		}
+	}
+}
+
+func TestEncodeBounds(t *testing.T) {
+	max := UUID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+	if s := Nil.EncodeBase58(); s != "1111111111111111111111" {
+		t.Errorf("Incorrect base58 encoding of Nil: %s", s)
+	}
+	if s := max.EncodeBase58(); s != "YcVfxkQb6JRzqk5kF2tNLv" {
+		t.Errorf("Incorrect base58 encoding of max UUID: %s", s)
+	}
+	if s := max.EncodeBase62(); s != "7n42DGM5Tflk9n8mt7Fhc7" {
+		t.Errorf("Incorrect base62 encoding of max UUID: %s", s)
+	}
+	if s := max.EncodeCrockford(); s != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("Incorrect crockford encoding of max UUID: %s", s)
+	}
+
+	for _, u := range []UUID{Nil, max, NewV4(), NewV7()} {
+		for _, enc := range []Encoding{EncodingBase58, EncodingBase62, EncodingCrockford, EncodingBase64URL} {
+			u2, err := Decode(u.Encode(enc), enc)
+			if err != nil || !Equal(u, u2) {
+				t.Errorf("Encoding %d did not round trip %s: got %s, %v", enc, u, u2, err)
+			}
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		in  string
+		enc Encoding
+	}{
+		{"EJ34kCVxxF9jHMKD4Egr", EncodingBase58},
+		{"EJ34kCVxxF9jHMKD4EgrA0", EncodingBase58},
+		{"YcVfxkQb6JRzqk5kF2tNLw", EncodingBase58},
+		{"3H8pGALtipnCnHud4zBik!", EncodingBase62},
+		{"7n42DGM5Tflk9n8mt7Fhc8", EncodingBase62},
+		{"8ZZZZZZZZZZZZZZZZZZZZZZZZZ", EncodingCrockford},
+		{"3BMYW117DD278R1D00R17X8C6U", EncodingCrockford},
+		{"a6e4EJ2tEdGAtADAT9QwyB", EncodingBase64URL},
+		{"a6e4EJ2tEdGAtADAT9Qwy", EncodingBase64URL},
+	}
+
+	for _, tt := range tests {
+		_, err := Decode(tt.in, tt.enc)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("Should return *ParseError decoding %s with encoding %d, got %v", tt.in, tt.enc, err)
+		}
+	}
+
+	if _, err := FromBase58("EJ34kCVxxF9jHMKD4Egr"); !errors.Is(err, ErrTooShort) {
+		t.Errorf("Should return ErrTooShort decoding short base58, got %v", err)
+	}
+	if _, err := FromBase62("3H8pGALtipnCnHud4zBik!"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Should return ErrInvalidFormat decoding invalid base62, got %v", err)
+	}
+}
+
+func TestFromCrockfordLenient(t *testing.T) {
+	u, err := FromCrockford("3bmyw117dd278r1door17x8c68")
+	if err != nil {
+		t.Fatalf("Error decoding lowercase crockford: %s", err)
+	}
+	if !Equal(u, NamespaceDNS) {
+		t.Errorf("Incorrect decoding of lowercase crockford: %s", u)
+	}
+}
+
+func TestParseAny(t *testing.T) {
+	inputs := []string{
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"{6ba7b810-9dad-11d1-80b4-00c04fd430c8}",
+		"urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"EJ34kCVxxF9jHMKD4EgrAK",
+		"3BMYW117DD278R1D00R17X8C68",
+	}
+
+	for _, in := range inputs {
+		u, err := ParseAny(in)
+		if err != nil {
+			t.Errorf("Error parsing %s: %s", in, err)
+			continue
+		}
+		if !Equal(u, NamespaceDNS) {
+			t.Errorf("Incorrect parsing of %s: %s", in, u)
+		}
+	}
+
+	u := UUID{0xfb, 0xef, 0xbe}
+	if u2, err := ParseAny(u.EncodeBase64URL()); err != nil || !Equal(u, u2) {
+		t.Errorf("Incorrect parsing of base64url %s: %s, %v", u.EncodeBase64URL(), u2, err)
+	}
+
+	if u, err := ParseAnyWith("3H8pGALtipnCnHud4zBiky", EncodingBase62); err != nil || !Equal(u, NamespaceDNS) {
+		t.Errorf("Incorrect parsing of base62 with ParseAnyWith: %s, %v", u, err)
+	}
+	if u, err := ParseAnyWith(NamespaceDNS.String(), EncodingBase62); err != nil || !Equal(u, NamespaceDNS) {
+		t.Errorf("Incorrect parsing of canonical form with ParseAnyWith: %s, %v", u, err)
+	}
+
+	if _, err := ParseAny("not-a-uuid"); err == nil {
+		t.Errorf("Should return error parsing invalid input")
+	}
+}
@@ -0,0 +1,382 @@
+package uuid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Generator generates time-based and random UUIDs from a configurable
+// random source, clock and node ID. A Generator is safe for concurrent use.
+// The package-level NewV1, NewV2, NewV4, NewV6 and NewV7 functions use a
+// default Generator backed by crypto/rand, the system clock and the MAC
+// address of the first network interface. The zero value uses the same
+// defaults.
+type Generator struct {
+	rand      io.Reader
+	epochFunc func() uint64
+	nodeFunc  func([]byte)
+	poolSize  int
+
+	// UUID v1/v2/v6 storage.
+	storageMutex  sync.Mutex
+	storageOnce   sync.Once
+	clockSequence uint16
+	lastTime      uint64
+	hardwareAddr  [6]byte
+
+	// UUID v7 storage.
+	v7Mutex    sync.Mutex
+	v7LastTime uint64
+	v7Sequence uint16
+}
+
+// GeneratorOption configures a Generator created with NewGenerator.
+type GeneratorOption func(*Generator)
+
+// WithRandom sets the source of randomness used for V4 and V7 UUIDs,
+// clock sequence initialization and random node IDs. Generation panics
+// if the reader returns an error, as it does for crypto/rand failures.
+func WithRandom(r io.Reader) GeneratorOption {
+	return func(g *Generator) {
+		g.rand = r
+	}
+}
+
+// WithClock sets the function used to read the current time for
+// V1, V2, V6 and V7 UUIDs.
+func WithClock(clock func() time.Time) GeneratorOption {
+	return func(g *Generator) {
+		g.epochFunc = func() uint64 {
+			return epochStart + uint64(clock().UnixNano()/100)
+		}
+	}
+}
+
+// WithNodeID sets the node ID used for V1, V2 and V6 UUIDs instead of
+// the MAC address of a network interface. Only the first 6 bytes are used.
+func WithNodeID(node net.HardwareAddr) GeneratorOption {
+	return func(g *Generator) {
+		g.nodeFunc = func(dest []byte) {
+			copy(dest, node)
+		}
+	}
+}
+
+// WithRandomNodeID uses a random node ID with the multicast bit set for
+// V1, V2 and V6 UUIDs, so generated UUIDs do not leak the host MAC address.
+func WithRandomNodeID() GeneratorOption {
+	return func(g *Generator) {
+		g.nodeFunc = g.randomNodeID
+	}
+}
+
+// WithRandomPool buffers reads from the random source in chunks of size bytes,
+// amortizing the cost of reading from crypto/rand over many UUIDs. Buffers are
+// pooled per processor, so parallel generation does not contend on a lock.
+// Random bytes stay in process memory until used, so a pooled Generator
+// should not share its random source with key generation.
+func WithRandomPool(size int) GeneratorOption {
+	return func(g *Generator) {
+		g.poolSize = size
+	}
+}
+
+// NewGenerator returns a Generator configured with the provided options.
+// Unconfigured settings default to the same sources as the package-level functions.
+func NewGenerator(opts ...GeneratorOption) *Generator {
+	g := &Generator{
+		rand: rand.Reader,
+		epochFunc: func() uint64 {
+			return epochFunc()
+		},
+	}
+	g.nodeFunc = g.interfaceNodeID
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.poolSize > 0 {
+		g.rand = newPooledReader(g.rand, g.poolSize)
+	}
+	return g
+}
+
+var defaultGenerator = NewGenerator()
+
+// Maximum number of UUIDs Fill reads randomness for at once.
+const fillChunk = 4096
+
+// pooledReader buffers reads from an underlying reader in per-processor buffers.
+type pooledReader struct {
+	r    io.Reader
+	size int
+	pool sync.Pool
+}
+
+type randomBuffer struct {
+	buf []byte
+	off int
+}
+
+func newPooledReader(r io.Reader, size int) *pooledReader {
+	p := &pooledReader{r: r, size: size}
+	p.pool.New = func() interface{} {
+		// Start empty so the first read fills the buffer.
+		return &randomBuffer{buf: make([]byte, size), off: size}
+	}
+	return p
+}
+
+// Read implements the io.Reader interface. It never returns
+// buffered bytes twice and reads large requests directly.
+func (p *pooledReader) Read(dest []byte) (int, error) {
+	if len(dest) > p.size {
+		return io.ReadFull(p.r, dest)
+	}
+
+	b := p.pool.Get().(*randomBuffer)
+	defer p.pool.Put(b)
+
+	if len(b.buf)-b.off < len(dest) {
+		if _, err := io.ReadFull(p.r, b.buf); err != nil {
+			b.off = len(b.buf)
+			return 0, err
+		}
+		b.off = 0
+	}
+	n := copy(dest, b.buf[b.off:])
+	b.off += n
+
+	return n, nil
+}
+
+func (g *Generator) safeRandom(dest []byte) {
+	r := g.rand
+	if r == nil {
+		r = rand.Reader
+	}
+	if _, err := io.ReadFull(r, dest); err != nil {
+		panic(err)
+	}
+}
+
+func (g *Generator) initClockSequence() {
+	buf := make([]byte, 2)
+	g.safeRandom(buf)
+	g.clockSequence = binary.BigEndian.Uint16(buf)
+}
+
+func (g *Generator) interfaceNodeID(dest []byte) {
+	interfaces, err := net.Interfaces()
+	if err == nil {
+		for _, iface := range interfaces {
+			if len(iface.HardwareAddr) >= 6 {
+				copy(dest, iface.HardwareAddr)
+				return
+			}
+		}
+	}
+
+	// Initialize node ID randomly in case
+	// of real network interfaces absence
+	g.randomNodeID(dest)
+}
+
+func (g *Generator) randomNodeID(dest []byte) {
+	g.safeRandom(dest)
+
+	// Set multicast bit as recommended in RFC 4122
+	dest[0] |= 0x01
+}
+
+func (g *Generator) initStorage() {
+	g.initClockSequence()
+	if g.nodeFunc != nil {
+		g.nodeFunc(g.hardwareAddr[:])
+	} else {
+		g.interfaceNodeID(g.hardwareAddr[:])
+	}
+}
+
+// Returns the current epoch timestamp from the configured clock,
+// or from the system clock for a zero Generator.
+func (g *Generator) epoch() uint64 {
+	if g.epochFunc == nil {
+		return epochFunc()
+	}
+	return g.epochFunc()
+}
+
+// Returns UUID v1/v2/v6 storage state.
+// Returns epoch timestamp, clock sequence, and hardware address.
+func (g *Generator) getStorage() (uint64, uint16, []byte) {
+	g.storageOnce.Do(g.initStorage)
+
+	g.storageMutex.Lock()
+	defer g.storageMutex.Unlock()
+
+	timeNow := g.epoch()
+	// Clock changed backwards since last UUID generation.
+	// Should increase clock sequence.
+	if timeNow <= g.lastTime {
+		g.clockSequence++
+	}
+	g.lastTime = timeNow
+
+	return timeNow, g.clockSequence, g.hardwareAddr[:]
+}
+
+// Returns UUID v7 storage state.
+// Returns Unix timestamp in milliseconds and a 12-bit sequence that
+// increases monotonically for UUIDs generated within the same millisecond.
+func (g *Generator) getV7Storage() (uint64, uint16) {
+	g.v7Mutex.Lock()
+	defer g.v7Mutex.Unlock()
+
+	timeNow := (g.epoch() - epochStart) / 10000
+	if timeNow > g.v7LastTime {
+		// Start each millisecond at a random point in the lower half
+		// of the sequence space to leave room for increments.
+		buf := make([]byte, 2)
+		g.safeRandom(buf)
+		g.v7Sequence = binary.BigEndian.Uint16(buf) & 0x07ff
+		g.v7LastTime = timeNow
+		return g.v7LastTime, g.v7Sequence
+	}
+
+	// Same millisecond, or clock changed backwards since last UUID generation.
+	// Keep using the last timestamp and borrow from the next millisecond
+	// when the sequence overflows, so ordering is preserved.
+	g.v7Sequence++
+	if g.v7Sequence > 0x0fff {
+		g.v7Sequence = 0
+		g.v7LastTime++
+	}
+
+	return g.v7LastTime, g.v7Sequence
+}
+
+// NewV1 returns UUID based on current timestamp and node ID.
+func (g *Generator) NewV1() UUID {
+	u := UUID{}
+
+	timeNow, clockSeq, hardwareAddr := g.getStorage()
+
+	binary.BigEndian.PutUint32(u[0:], uint32(timeNow))
+	binary.BigEndian.PutUint16(u[4:], uint16(timeNow>>32))
+	binary.BigEndian.PutUint16(u[6:], uint16(timeNow>>48))
+	binary.BigEndian.PutUint16(u[8:], clockSeq)
+
+	copy(u[10:], hardwareAddr)
+
+	u.SetVersion(1)
+	u.SetVariant()
+
+	return u
+}
+
+// NewV2 returns DCE Security UUID based on POSIX UID/GID.
+func (g *Generator) NewV2(domain byte) UUID {
+	u := UUID{}
+
+	timeNow, clockSeq, hardwareAddr := g.getStorage()
+
+	switch domain {
+	case DomainPerson:
+		binary.BigEndian.PutUint32(u[0:], posixUID)
+	case DomainGroup:
+		binary.BigEndian.PutUint32(u[0:], posixGID)
+	}
+
+	binary.BigEndian.PutUint16(u[4:], uint16(timeNow>>32))
+	binary.BigEndian.PutUint16(u[6:], uint16(timeNow>>48))
+	binary.BigEndian.PutUint16(u[8:], clockSeq)
+	u[9] = domain
+
+	copy(u[10:], hardwareAddr)
+
+	u.SetVersion(2)
+	u.SetVariant()
+
+	return u
+}
+
+// NewV4 returns random generated UUID.
+func (g *Generator) NewV4() UUID {
+	u := UUID{}
+	g.safeRandom(u[:])
+	u.SetVersion(4)
+	u.SetVariant()
+
+	return u
+}
+
+// Fill sets each element of uuids to a random generated UUID,
+// reading randomness for many UUIDs at once.
+func (g *Generator) Fill(uuids []UUID) {
+	buf := make([]byte, 16*min(len(uuids), fillChunk))
+
+	for len(uuids) > 0 {
+		n := min(len(uuids), fillChunk)
+		g.safeRandom(buf[:16*n])
+		for i := 0; i < n; i++ {
+			u := &uuids[i]
+			copy(u[:], buf[16*i:])
+			u.SetVersion(4)
+			u.SetVariant()
+		}
+		uuids = uuids[n:]
+	}
+}
+
+// NewV4Batch returns n random generated UUIDs.
+func (g *Generator) NewV4Batch(n int) []UUID {
+	uuids := make([]UUID, n)
+	g.Fill(uuids)
+
+	return uuids
+}
+
+// NewV6 returns UUID based on current timestamp and node ID with
+// the timestamp fields reordered so UUIDs sort by creation time,
+// as described in RFC 9562.
+func (g *Generator) NewV6() UUID {
+	u := UUID{}
+
+	timeNow, clockSeq, hardwareAddr := g.getStorage()
+
+	binary.BigEndian.PutUint32(u[0:], uint32(timeNow>>28))
+	binary.BigEndian.PutUint16(u[4:], uint16(timeNow>>12))
+	binary.BigEndian.PutUint16(u[6:], uint16(timeNow&0x0fff))
+	binary.BigEndian.PutUint16(u[8:], clockSeq)
+
+	copy(u[10:], hardwareAddr)
+
+	u.SetVersion(6)
+	u.SetVariant()
+
+	return u
+}
+
+// NewV7 returns time-ordered UUID based on Unix timestamp in milliseconds
+// as described in RFC 9562. UUIDs generated by the same Generator sort in
+// generation order, including within the same millisecond.
+func (g *Generator) NewV7() UUID {
+	u := UUID{}
+
+	timeNow, seq := g.getV7Storage()
+
+	u[0] = byte(timeNow >> 40)
+	u[1] = byte(timeNow >> 32)
+	binary.BigEndian.PutUint32(u[2:], uint32(timeNow))
+	binary.BigEndian.PutUint16(u[6:], seq)
+	g.safeRandom(u[8:])
+
+	u.SetVersion(7)
+	u.SetVariant()
+
+	return u
+}
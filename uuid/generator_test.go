@@ -0,0 +1,209 @@
+package uuid
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+// fixedClock returns a clock reporting 2022-02-22 19:22:22 UTC,
+// the time used by the RFC 9562 test vectors.
+func fixedClock() time.Time {
+	return time.Date(2022, time.February, 22, 19, 22, 22, 0, time.UTC)
+}
+
+func TestGeneratorDeterministic(t *testing.T) {
+	node := net.HardwareAddr{0x9f, 0x6b, 0xde, 0xce, 0xd8, 0x46}
+	newGenerator := func() *Generator {
+		return NewGenerator(
+			WithRandom(bytes.NewReader(bytes.Repeat([]byte{0x33, 0xc8}, 64))),
+			WithClock(fixedClock),
+			WithNodeID(node),
+		)
+	}
+
+	g := newGenerator()
+	if u := g.NewV1(); u.String() != "c232ab00-9414-11ec-b3c8-9f6bdeced846" {
+		t.Errorf("Generator produced unexpected UUIDv1: %s", u)
+	}
+
+	g = newGenerator()
+	if u := g.NewV6(); u.String() != "1ec9414c-232a-6b00-b3c8-9f6bdeced846" {
+		t.Errorf("Generator produced unexpected UUIDv6: %s", u)
+	}
+
+	g = newGenerator()
+	if u := g.NewV4(); u.String() != "33c833c8-33c8-43c8-b3c8-33c833c833c8" {
+		t.Errorf("Generator produced unexpected UUIDv4: %s", u)
+	}
+
+	u1 := newGenerator().NewV7()
+	u2 := newGenerator().NewV7()
+	if !Equal(u1, u2) {
+		t.Errorf("Generators with same configuration produced different UUIDv7: %s and %s", u1, u2)
+	}
+	if ts, _ := u1.Timestamp(); !ts.Equal(fixedClock()) {
+		t.Errorf("Generator produced UUIDv7 with unexpected timestamp: %s", ts)
+	}
+}
+
+func TestGeneratorClockSequence(t *testing.T) {
+	g := NewGenerator(WithClock(fixedClock))
+
+	u1 := g.NewV1()
+	u2 := g.NewV1()
+
+	if Equal(u1, u2) {
+		t.Errorf("Generator with fixed clock generated two equal UUIDs: %s and %s", u1, u2)
+	}
+}
+
+func TestGeneratorRandomNodeID(t *testing.T) {
+	g := NewGenerator(WithRandomNodeID())
+
+	node, err := g.NewV1().NodeID()
+	if err != nil {
+		t.Fatalf("Error extracting node ID: %s", err)
+	}
+	if node[0]&0x01 != 0x01 {
+		t.Errorf("Random node ID should have multicast bit set: %s", node)
+	}
+
+	node2, _ := g.NewV6().NodeID()
+	if !bytes.Equal(node, node2) {
+		t.Errorf("Generator changed node ID between UUIDs: %s and %s", node, node2)
+	}
+}
+
+func TestGeneratorZeroValue(t *testing.T) {
+	var g Generator
+
+	for _, u := range []UUID{g.NewV1(), g.NewV4(), g.NewV6(), g.NewV7()} {
+		if Equal(u, Nil) {
+			t.Errorf("Zero Generator produced a nil UUID")
+		}
+	}
+	if u := g.NewV7(); u.Version() != V7 {
+		t.Errorf("Zero Generator produced UUID version %d, expected %d", u.Version(), V7)
+	}
+}
+
+func TestGeneratorRandomFailure(t *testing.T) {
+	g := NewGenerator(WithRandom(bytes.NewReader(nil)))
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Generator should panic when random source fails")
+		}
+	}()
+	g.NewV4()
+}
+
+func TestFill(t *testing.T) {
+	uuids := make([]UUID, fillChunk+3)
+	Fill(uuids)
+
+	seen := make(map[UUID]bool, len(uuids))
+	for _, u := range uuids {
+		if u.Version() != 4 || u.Variant() != VariantRFC4122 {
+			t.Fatalf("Fill generated UUID with incorrect version or variant: %s", u)
+		}
+		seen[u] = true
+	}
+	if len(seen) != len(uuids) {
+		t.Errorf("Fill generated %d duplicates", len(uuids)-len(seen))
+	}
+
+	if n := len(NewV4Batch(0)); n != 0 {
+		t.Errorf("NewV4Batch(0) returned %d UUIDs", n)
+	}
+}
+
+func TestNewV4BatchDeterministic(t *testing.T) {
+	random := make([]byte, 16*3)
+	for i := range random {
+		random[i] = byte(i)
+	}
+
+	batch := NewGenerator(WithRandom(bytes.NewReader(random))).NewV4Batch(3)
+
+	g := NewGenerator(WithRandom(bytes.NewReader(random)))
+	for i, u := range batch {
+		if want := g.NewV4(); !Equal(u, want) {
+			t.Errorf("NewV4Batch UUID %d should be %s, got %s", i, want, u)
+		}
+	}
+}
+
+// counterReader produces an endless stream of distinct 16-byte blocks.
+type counterReader struct {
+	n uint64
+}
+
+func (c *counterReader) Read(p []byte) (int, error) {
+	for i := range p {
+		if i%16 == 8 {
+			c.n++
+		}
+		p[i] = byte(c.n >> (8 * (7 - i%8)))
+		if i%16 < 8 {
+			p[i] = 0
+		}
+	}
+	return len(p), nil
+}
+
+func TestRandomPool(t *testing.T) {
+	g := NewGenerator(WithRandom(&counterReader{}), WithRandomPool(64))
+
+	seen := make(map[UUID]bool)
+	for i := 0; i < 100; i++ {
+		u := g.NewV4()
+		if u.Version() != 4 || u.Variant() != VariantRFC4122 {
+			t.Fatalf("Pooled generator generated UUID with incorrect version or variant: %s", u)
+		}
+		if !bytes.Equal(u[:6], make([]byte, 6)) {
+			t.Fatalf("Pooled generator did not read whole blocks from source: %s", u)
+		}
+		if seen[u] {
+			t.Fatalf("Pooled generator reused random bytes: %s", u)
+		}
+		seen[u] = true
+	}
+
+	// Reads larger than the pool go to the source directly.
+	if n := len(g.NewV4Batch(10)); n != 10 {
+		t.Errorf("Pooled generator returned %d UUIDs for batch of 10", n)
+	}
+
+	g = NewGenerator(WithRandom(bytes.NewReader(nil)), WithRandomPool(64))
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Pooled generator should panic when random source fails")
+		}
+	}()
+	g.NewV4()
+}
+
+func TestRandomPoolConcurrent(t *testing.T) {
+	g := NewGenerator(WithRandomPool(1024))
+
+	const workers, perWorker = 8, 1000
+	results := make(chan UUID, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := 0; i < perWorker; i++ {
+				results <- g.NewV4()
+			}
+		}()
+	}
+
+	seen := make(map[UUID]bool, workers*perWorker)
+	for i := 0; i < workers*perWorker; i++ {
+		seen[<-results] = true
+	}
+	if len(seen) != workers*perWorker {
+		t.Errorf("Pooled generator generated %d duplicates", workers*perWorker-len(seen))
+	}
+}
@@ -0,0 +1,110 @@
+package uuid
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+var (
+	jsonNull        = []byte("null")
+	jsonEmptyString = []byte(`""`)
+)
+
+// EmptyAsNilUUID is a UUID that decodes the JSON values "" and null as Nil,
+// for API payloads that send an empty string instead of omitting the ID.
+// It encodes like UUID.
+type EmptyAsNilUUID struct {
+	UUID
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// "" and null decode as Nil, other strings are parsed by UnmarshalText.
+func (u *EmptyAsNilUUID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, jsonNull) || bytes.Equal(data, jsonEmptyString) {
+		u.UUID = Nil
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// Report the UUID type, like for UUID fields, rather than string
+		return &json.UnmarshalTypeError{Value: jsonKind(data), Type: reflect.TypeOf(UUID{})}
+	}
+	return u.UUID.UnmarshalText([]byte(s))
+}
+
+// Returns the kind of a JSON value as reported by json.UnmarshalTypeError.
+func jsonKind(data []byte) string {
+	switch data = bytes.TrimSpace(data); {
+	case len(data) == 0:
+		return "value"
+	case data[0] == '"':
+		return "string"
+	case data[0] == '{':
+		return "object"
+	case data[0] == '[':
+		return "array"
+	case data[0] == 't' || data[0] == 'f':
+		return "bool"
+	}
+	return "number"
+}
+
+// Marshals a nullable UUID as a JSON string, or null if it is not valid.
+func marshalNullJSON(u UUID, valid bool) ([]byte, error) {
+	if !valid {
+		return jsonNull, nil
+	}
+	return json.Marshal(u)
+}
+
+// Unmarshals a nullable UUID from a JSON string or null.
+func unmarshalNullJSON(data []byte, u *UUID, valid *bool) error {
+	if bytes.Equal(data, jsonNull) {
+		*u, *valid = Nil, false
+		return nil
+	}
+
+	if err := json.Unmarshal(data, u); err != nil {
+		return err
+	}
+	*valid = true
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// An invalid NullUUID is encoded as null.
+func (u NullUUID) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(u.UUID, u.Valid)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// null decodes to an invalid NullUUID.
+func (u *NullUUID) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &u.UUID, &u.Valid)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// An invalid NullBinaryUUID is encoded as null.
+func (u NullBinaryUUID) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(u.UUID, u.Valid)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// null decodes to an invalid NullBinaryUUID.
+func (u *NullBinaryUUID) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &u.UUID, &u.Valid)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// An invalid NullOrderedBinaryUUID is encoded as null.
+func (u NullOrderedBinaryUUID) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(u.UUID, u.Valid)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// null decodes to an invalid NullOrderedBinaryUUID.
+func (u *NullOrderedBinaryUUID) UnmarshalJSON(data []byte) error {
+	return unmarshalNullJSON(data, &u.UUID, &u.Valid)
+}
@@ -0,0 +1,160 @@
+package uuid
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNullUUIDMarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{NullUUID{}, `null`},
+		{NullUUID{UUID: NamespaceDNS, Valid: true}, `"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`},
+		{NullBinaryUUID{}, `null`},
+		{NullOrderedBinaryUUID{UUID: NamespaceDNS, Valid: true}, `"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`},
+		{struct {
+			ID NullUUID `json:"id"`
+		}{}, `{"id":null}`},
+		{BinaryUUID{NamespaceDNS}, `"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`},
+	}
+
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.in)
+		if err != nil {
+			t.Errorf("Error marshaling %#v: %s", tt.in, err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshaled %#v should be %s, got %s", tt.in, tt.want, b)
+		}
+	}
+}
+
+func TestNullUUIDUnmarshalJSON(t *testing.T) {
+	var u NullUUID
+
+	if err := json.Unmarshal([]byte(`"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`), &u); err != nil {
+		t.Errorf("Error unmarshaling NullUUID: %s", err)
+	}
+	if !u.Valid || !Equal(u.UUID, NamespaceDNS) {
+		t.Errorf("Unmarshaled NullUUID should be valid %s, got %v", NamespaceDNS, u)
+	}
+
+	if err := json.Unmarshal([]byte(`null`), &u); err != nil {
+		t.Errorf("Error unmarshaling null NullUUID: %s", err)
+	}
+	if u.Valid || !Equal(u.UUID, Nil) {
+		t.Errorf("Unmarshaled null NullUUID should be invalid, got %v", u)
+	}
+
+	if err := json.Unmarshal([]byte(`""`), &u); !errors.Is(err, ErrTooShort) {
+		t.Errorf("Unmarshaling empty NullUUID should return ErrTooShort, got %v", err)
+	}
+
+	if err := json.Unmarshal([]byte(`42`), &u); err == nil {
+		t.Errorf("Should return error unmarshaling NullUUID from number")
+	}
+
+	var b NullOrderedBinaryUUID
+	if err := json.Unmarshal([]byte(`"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`), &b); err != nil || !b.Valid {
+		t.Errorf("Error unmarshaling NullOrderedBinaryUUID: %v, %v", b, err)
+	}
+}
+
+func TestUUIDUnmarshalJSON(t *testing.T) {
+	var s struct {
+		ID UUID `json:"id"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8"}`), &s); err != nil {
+		t.Errorf("Error unmarshaling UUID: %s", err)
+	}
+	if !Equal(s.ID, NamespaceDNS) {
+		t.Errorf("UUIDs should be equal: %s and %s", s.ID, NamespaceDNS)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":null}`), &s); err != nil {
+		t.Errorf("Error unmarshaling null UUID: %s", err)
+	}
+	if !Equal(s.ID, NamespaceDNS) {
+		t.Errorf("Unmarshaling null should leave UUID unchanged, got %s", s.ID)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":""}`), &s); err == nil {
+		t.Errorf("Should return error unmarshaling empty UUID")
+	}
+}
+
+func TestEmptyAsNilUUID(t *testing.T) {
+	var s struct {
+		ID   EmptyAsNilUUID `json:"id"`
+		Null NullUUID       `json:"null"`
+	}
+
+	for _, in := range []string{`{"id":""}`, `{"id":null}`} {
+		s.ID = EmptyAsNilUUID{NamespaceDNS}
+
+		if err := json.Unmarshal([]byte(in), &s); err != nil {
+			t.Errorf("Error unmarshaling %s: %s", in, err)
+		}
+		if !Equal(s.ID.UUID, Nil) {
+			t.Errorf("Unmarshaling %s should set EmptyAsNilUUID to Nil, got %s", in, s.ID)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8"}`), &s); err != nil {
+		t.Errorf("Error unmarshaling EmptyAsNilUUID: %s", err)
+	}
+	if !Equal(s.ID.UUID, NamespaceDNS) {
+		t.Errorf("Unmarshaled EmptyAsNilUUID should be %s, got %s", NamespaceDNS, s.ID)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":"not-a-uuid"}`), &s); err == nil {
+		t.Errorf("Should return error unmarshaling invalid EmptyAsNilUUID")
+	}
+
+	// The option is scoped to the type, other UUIDs keep rejecting ""
+	if err := json.Unmarshal([]byte(`{"null":""}`), &s); err == nil {
+		t.Errorf("Should return error unmarshaling empty NullUUID")
+	}
+
+	b, err := json.Marshal(EmptyAsNilUUID{NamespaceDNS})
+	if err != nil || string(b) != `"6ba7b810-9dad-11d1-80b4-00c04fd430c8"` {
+		t.Errorf("Marshaled EmptyAsNilUUID should be a JSON string, got %s, %v", b, err)
+	}
+}
+
+func TestUnmarshalJSONTypeError(t *testing.T) {
+	tests := []struct {
+		target interface{}
+		in     string
+		value  string
+		// encoding/json only names the field for values it decodes itself
+		field string
+	}{
+		{&struct {
+			ID UUID `json:"id"`
+		}{}, `{"id":5}`, "number", "id"},
+		{&struct {
+			ID NullUUID `json:"id"`
+		}{}, `{"id":true}`, "bool", ""},
+		{&struct {
+			ID EmptyAsNilUUID `json:"id"`
+		}{}, `{"id":{}}`, "object", ""},
+	}
+
+	for _, tt := range tests {
+		err := json.Unmarshal([]byte(tt.in), tt.target)
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			t.Errorf("Unmarshaling %s should return a *json.UnmarshalTypeError, got %v", tt.in, err)
+			continue
+		}
+		if typeErr.Field != tt.field || typeErr.Type != reflect.TypeOf(UUID{}) || typeErr.Value != tt.value {
+			t.Errorf("Unmarshaling %s should report %s for field %q of type uuid.UUID, got %s for field %q of type %s", tt.in, tt.value, tt.field, typeErr.Value, typeErr.Field, typeErr.Type)
+		}
+	}
+}
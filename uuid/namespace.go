@@ -0,0 +1,58 @@
+package uuid
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Namespace is a UUID namespace for deterministic V5 UUIDs within a domain,
+// so the same input produces the same UUID across services and imports.
+type Namespace UUID
+
+// NewNamespace returns the namespace identified by name, typically a URL
+// such as "exlinc.com/users". The namespace UUID is the V5 UUID of name
+// in the predefined NamespaceURL.
+func NewNamespace(name string) Namespace {
+	return Namespace(NewV5(NamespaceURL, name))
+}
+
+// NamespaceFromUUID returns the namespace identified by an existing UUID,
+// such as one of the predefined namespace UUIDs.
+func NamespaceFromUUID(u UUID) Namespace {
+	return Namespace(u)
+}
+
+// UUID returns the namespace UUID.
+func (ns Namespace) UUID() UUID {
+	return UUID(ns)
+}
+
+// String returns canonical string representation of the namespace UUID.
+func (ns Namespace) String() string {
+	return UUID(ns).String()
+}
+
+// ID returns V5 UUID of the provided parts in the namespace.
+// Each part is length-prefixed before hashing, so different splits of
+// the same characters, like ("a:b") and ("a", "b"), produce different UUIDs.
+func (ns Namespace) ID(parts ...string) UUID {
+	return NewV5(UUID(ns), joinParts(parts))
+}
+
+// Child returns the namespace nested in ns under name,
+// e.g. NewNamespace("exlinc.com").Child("users").
+func (ns Namespace) Child(name string) Namespace {
+	return Namespace(ns.ID(name))
+}
+
+// Joins parts as netstrings, "<length>:<part>,", which cannot be ambiguous.
+func joinParts(parts []string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(strconv.Itoa(len(part)))
+		b.WriteByte(':')
+		b.WriteString(part)
+		b.WriteByte(',')
+	}
+	return b.String()
+}
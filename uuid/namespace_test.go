@@ -0,0 +1,77 @@
+package uuid
+
+import (
+	"testing"
+)
+
+func TestNewNamespace(t *testing.T) {
+	ns := NewNamespace("exlinc.com/users")
+
+	if !Equal(ns.UUID(), NewV5(NamespaceURL, "exlinc.com/users")) {
+		t.Errorf("Namespace should be V5 UUID of its name in NamespaceURL, got %s", ns)
+	}
+	if ns.String() != ns.UUID().String() {
+		t.Errorf("Namespace string should be its UUID string, got %s", ns.String())
+	}
+	if NewNamespace("exlinc.com/users") != ns {
+		t.Errorf("Namespaces with same name should be equal")
+	}
+	if NewNamespace("exlinc.com/courses") == ns {
+		t.Errorf("Namespaces with different names should differ")
+	}
+	if NamespaceFromUUID(NamespaceDNS).UUID() != NamespaceDNS {
+		t.Errorf("Namespace from UUID should keep the UUID")
+	}
+}
+
+func TestNamespaceID(t *testing.T) {
+	ns := NewNamespace("exlinc.com/users")
+
+	u := ns.ID("tenant-1", "alice@example.com")
+	if u.Version() != 5 || u.Variant() != VariantRFC4122 {
+		t.Errorf("Namespace ID generated with incorrect version or variant: %s", u)
+	}
+	if !Equal(u, ns.ID("tenant-1", "alice@example.com")) {
+		t.Errorf("Namespace ID should be deterministic")
+	}
+	if !Equal(u, NewV5(ns.UUID(), "8:tenant-1,17:alice@example.com,")) {
+		t.Errorf("Namespace ID should hash length-prefixed parts, got %s", u)
+	}
+
+	ambiguous := [][]string{
+		{"a:b"},
+		{"a", "b"},
+		{"a", ":b"},
+		{"a:", "b"},
+		{"ab"},
+		{"ab", ""},
+		{"", "ab"},
+	}
+	seen := make(map[UUID][]string)
+	for _, parts := range ambiguous {
+		id := ns.ID(parts...)
+		if prev, ok := seen[id]; ok {
+			t.Errorf("Namespace ID of %q and %q should differ", prev, parts)
+		}
+		seen[id] = parts
+	}
+
+	if Equal(ns.ID("x"), NewNamespace("exlinc.com/courses").ID("x")) {
+		t.Errorf("Namespace IDs in different namespaces should differ")
+	}
+}
+
+func TestNamespaceChild(t *testing.T) {
+	parent := NewNamespace("exlinc.com")
+	child := parent.Child("users")
+
+	if child == parent {
+		t.Errorf("Child namespace should differ from its parent")
+	}
+	if child != parent.Child("users") {
+		t.Errorf("Child namespace should be deterministic")
+	}
+	if Equal(child.ID("alice"), parent.ID("alice")) {
+		t.Errorf("IDs in child and parent namespaces should differ")
+	}
+}
@@ -0,0 +1,105 @@
+package uuid
+
+import "sort"
+
+// Slice attaches the methods of sort.Interface to []UUID, sorting in increasing byte-wise order.
+type Slice []UUID
+
+func (s Slice) Len() int           { return len(s) }
+func (s Slice) Less(i, j int) bool { return Less(s[i], s[j]) }
+func (s Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Sort sorts the slice in increasing byte-wise order.
+func (s Slice) Sort() {
+	sort.Sort(s)
+}
+
+// Set is a set of UUIDs. The zero value is an empty set that is
+// ready for reads, use NewSet or make(Set) before adding UUIDs.
+type Set map[UUID]struct{}
+
+// NewSet returns a set containing the provided UUIDs.
+func NewSet(uuids ...UUID) Set {
+	s := make(Set, len(uuids))
+	s.Add(uuids...)
+
+	return s
+}
+
+// Add adds the provided UUIDs to the set.
+func (s Set) Add(uuids ...UUID) {
+	for _, u := range uuids {
+		s[u] = struct{}{}
+	}
+}
+
+// Remove removes the provided UUIDs from the set.
+func (s Set) Remove(uuids ...UUID) {
+	for _, u := range uuids {
+		delete(s, u)
+	}
+}
+
+// Contains returns true if u is in the set.
+func (s Set) Contains(u UUID) bool {
+	_, ok := s[u]
+	return ok
+}
+
+// Len returns the number of UUIDs in the set.
+func (s Set) Len() int {
+	return len(s)
+}
+
+// Union returns a new set containing the UUIDs in s or other.
+func (s Set) Union(other Set) Set {
+	u := make(Set, len(s)+len(other))
+	for id := range s {
+		u[id] = struct{}{}
+	}
+	for id := range other {
+		u[id] = struct{}{}
+	}
+
+	return u
+}
+
+// Intersection returns a new set containing the UUIDs in both s and other.
+func (s Set) Intersection(other Set) Set {
+	// Iterate over the smaller set.
+	if len(other) < len(s) {
+		s, other = other, s
+	}
+
+	i := make(Set)
+	for id := range s {
+		if other.Contains(id) {
+			i[id] = struct{}{}
+		}
+	}
+
+	return i
+}
+
+// Difference returns a new set containing the UUIDs in s that are not in other.
+func (s Set) Difference(other Set) Set {
+	d := make(Set)
+	for id := range s {
+		if !other.Contains(id) {
+			d[id] = struct{}{}
+		}
+	}
+
+	return d
+}
+
+// Slice returns the UUIDs in the set in sorted order.
+func (s Set) Slice() Slice {
+	sl := make(Slice, 0, len(s))
+	for id := range s {
+		sl = append(sl, id)
+	}
+	sl.Sort()
+
+	return sl
+}
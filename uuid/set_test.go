@@ -0,0 +1,91 @@
+package uuid
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	if Compare(NamespaceDNS, NamespaceDNS) != 0 {
+		t.Errorf("Incorrect comparison of %s and %s", NamespaceDNS, NamespaceDNS)
+	}
+	if Compare(NamespaceDNS, NamespaceURL) != -1 || !Less(NamespaceDNS, NamespaceURL) {
+		t.Errorf("%s should sort before %s", NamespaceDNS, NamespaceURL)
+	}
+	if Compare(NamespaceURL, NamespaceDNS) != 1 || Less(NamespaceURL, NamespaceDNS) {
+		t.Errorf("%s should sort after %s", NamespaceURL, NamespaceDNS)
+	}
+	if !Less(Nil, Max) {
+		t.Errorf("Nil should sort before Max")
+	}
+}
+
+func TestSlice(t *testing.T) {
+	s := Slice{NamespaceX500, Max, NamespaceURL, Nil, NamespaceDNS, NamespaceOID}
+	s.Sort()
+
+	want := Slice{Nil, NamespaceDNS, NamespaceURL, NamespaceOID, NamespaceX500, Max}
+	for i := range want {
+		if !Equal(s[i], want[i]) {
+			t.Errorf("Sorted slice element %d should be %s, got %s", i, want[i], s[i])
+		}
+	}
+
+	g := NewGenerator()
+	ids := make(Slice, 100)
+	for i := range ids {
+		ids[i] = g.NewV7()
+	}
+	if !sort.IsSorted(ids) {
+		t.Errorf("UUIDv7 generated in order should be sorted")
+	}
+}
+
+func TestSet(t *testing.T) {
+	s := NewSet(NamespaceDNS, NamespaceURL, NamespaceDNS)
+
+	if s.Len() != 2 {
+		t.Errorf("Set should de-duplicate UUIDs, got %d elements", s.Len())
+	}
+	if !s.Contains(NamespaceDNS) || !s.Contains(NamespaceURL) || s.Contains(NamespaceOID) {
+		t.Errorf("Incorrect set membership: %v", s.Slice())
+	}
+
+	s.Add(NamespaceOID)
+	s.Remove(NamespaceURL, NamespaceX500)
+	if got := s.Slice(); len(got) != 2 || !Equal(got[0], NamespaceDNS) || !Equal(got[1], NamespaceOID) {
+		t.Errorf("Set should contain %s and %s, got %v", NamespaceDNS, NamespaceOID, got)
+	}
+
+	var empty Set
+	if empty.Contains(NamespaceDNS) || empty.Len() != 0 {
+		t.Errorf("Zero value Set should be empty")
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	a := NewSet(NamespaceDNS, NamespaceURL, NamespaceOID)
+	b := NewSet(NamespaceOID, NamespaceX500)
+
+	union := a.Union(b)
+	if union.Len() != 4 {
+		t.Errorf("Union should have 4 elements, got %v", union.Slice())
+	}
+
+	inter := a.Intersection(b)
+	if inter.Len() != 1 || !inter.Contains(NamespaceOID) {
+		t.Errorf("Intersection should be {%s}, got %v", NamespaceOID, inter.Slice())
+	}
+	if !b.Intersection(a).Contains(NamespaceOID) {
+		t.Errorf("Intersection should be commutative")
+	}
+
+	diff := a.Difference(b)
+	if diff.Len() != 2 || diff.Contains(NamespaceOID) {
+		t.Errorf("Difference should be {%s, %s}, got %v", NamespaceDNS, NamespaceURL, diff.Slice())
+	}
+
+	if a.Len() != 3 || b.Len() != 2 {
+		t.Errorf("Set operations should not modify their operands")
+	}
+}
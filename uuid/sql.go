@@ -0,0 +1,126 @@
+package uuid
+
+import (
+	"database/sql/driver"
+)
+
+// BinaryUUID can be used with the standard sql package to store a UUID
+// as 16 raw bytes, e.g. in a MySQL BINARY(16) column, instead of the
+// 36-character string written by UUID.
+type BinaryUUID struct {
+	UUID
+}
+
+// OrderedBinaryUUID can be used with the standard sql package to store a UUID
+// as 16 bytes with the time-low and time-high fields swapped, compatible with
+// MySQL UUID_TO_BIN(uuid, 1) and BIN_TO_UUID(bin, 1). For V1 UUIDs this puts
+// the most significant time bits first, so rows are inserted in index order.
+type OrderedBinaryUUID struct {
+	UUID
+}
+
+// NullBinaryUUID is the nullable counterpart of BinaryUUID.
+type NullBinaryUUID struct {
+	UUID  UUID
+	Valid bool
+}
+
+// NullOrderedBinaryUUID is the nullable counterpart of OrderedBinaryUUID.
+type NullOrderedBinaryUUID struct {
+	UUID  UUID
+	Valid bool
+}
+
+// Swaps the time-low and time-high fields of UUID as MySQL UUID_TO_BIN(uuid, 1) does.
+func swapTimeFields(u UUID) (s UUID) {
+	copy(s[0:2], u[6:8])
+	copy(s[2:4], u[4:6])
+	copy(s[4:8], u[0:4])
+	copy(s[8:], u[8:])
+
+	return
+}
+
+// Reverts swapTimeFields as MySQL BIN_TO_UUID(bin, 1) does.
+func unswapTimeFields(s UUID) (u UUID) {
+	copy(u[0:4], s[4:8])
+	copy(u[4:6], s[2:4])
+	copy(u[6:8], s[0:2])
+	copy(u[8:], s[8:])
+
+	return
+}
+
+// Value implements the driver.Valuer interface.
+func (u BinaryUUID) Value() (driver.Value, error) {
+	return u.Bytes(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+// It accepts the same input as UUID.Scan.
+func (u *BinaryUUID) Scan(src interface{}) error {
+	return u.UUID.Scan(src)
+}
+
+// Value implements the driver.Valuer interface.
+func (u OrderedBinaryUUID) Value() (driver.Value, error) {
+	s := swapTimeFields(u.UUID)
+	return s[:], nil
+}
+
+// Scan implements the sql.Scanner interface.
+// A 16-byte slice is expected to be time-swapped,
+// while a longer byte slice or a string is handled by UnmarshalText.
+func (u *OrderedBinaryUUID) Scan(src interface{}) error {
+	if b, ok := src.([]byte); ok && len(b) == 16 {
+		var s UUID
+		copy(s[:], b)
+		u.UUID = unswapTimeFields(s)
+		return nil
+	}
+	return u.UUID.Scan(src)
+}
+
+// Value implements the driver.Valuer interface.
+func (u NullBinaryUUID) Value() (driver.Value, error) {
+	if !u.Valid {
+		return nil, nil
+	}
+	return BinaryUUID{u.UUID}.Value()
+}
+
+// Scan implements the sql.Scanner interface.
+func (u *NullBinaryUUID) Scan(src interface{}) error {
+	if src == nil {
+		u.UUID, u.Valid = Nil, false
+		return nil
+	}
+
+	u.Valid = true
+	b := BinaryUUID{}
+	err := b.Scan(src)
+	u.UUID = b.UUID
+	return err
+}
+
+// Value implements the driver.Valuer interface.
+func (u NullOrderedBinaryUUID) Value() (driver.Value, error) {
+	if !u.Valid {
+		return nil, nil
+	}
+	return OrderedBinaryUUID{u.UUID}.Value()
+}
+
+// Scan implements the sql.Scanner interface.
+func (u *NullOrderedBinaryUUID) Scan(src interface{}) error {
+	if src == nil {
+		u.UUID, u.Valid = Nil, false
+		return nil
+	}
+
+	u.Valid = true
+	o := OrderedBinaryUUID{}
+	err := o.Scan(src)
+	u.UUID = o.UUID
+	return err
+}
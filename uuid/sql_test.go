@@ -0,0 +1,106 @@
+package uuid
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBinaryUUIDValue(t *testing.T) {
+	u := BinaryUUID{NamespaceDNS}
+
+	val, err := u.Value()
+	if err != nil {
+		t.Errorf("Error getting BinaryUUID value: %s", err)
+	}
+
+	b, ok := val.([]byte)
+	if !ok || !bytes.Equal(b, NamespaceDNS.Bytes()) {
+		t.Errorf("BinaryUUID value should be %x, got %v", NamespaceDNS.Bytes(), val)
+	}
+}
+
+func TestBinaryUUIDScan(t *testing.T) {
+	for _, src := range []interface{}{NamespaceDNS.Bytes(), NamespaceDNS.String(), []byte(NamespaceDNS.String())} {
+		u := BinaryUUID{}
+		if err := u.Scan(src); err != nil {
+			t.Errorf("Error scanning BinaryUUID from %v: %s", src, err)
+		}
+		if !Equal(u.UUID, NamespaceDNS) {
+			t.Errorf("UUIDs should be equal: %s and %s", u, NamespaceDNS)
+		}
+	}
+}
+
+func TestOrderedBinaryUUID(t *testing.T) {
+	// Example from the MySQL UUID_TO_BIN documentation.
+	u := OrderedBinaryUUID{FromStringOrNil("6ccd780c-baba-1026-9564-5b8c656024db")}
+	swapped := []byte{0x10, 0x26, 0xba, 0xba, 0x6c, 0xcd, 0x78, 0x0c, 0x95, 0x64, 0x5b, 0x8c, 0x65, 0x60, 0x24, 0xdb}
+
+	val, err := u.Value()
+	if err != nil {
+		t.Errorf("Error getting OrderedBinaryUUID value: %s", err)
+	}
+	if b, ok := val.([]byte); !ok || !bytes.Equal(b, swapped) {
+		t.Errorf("OrderedBinaryUUID value should be %x, got %v", swapped, val)
+	}
+
+	for _, src := range []interface{}{swapped, u.String()} {
+		u2 := OrderedBinaryUUID{}
+		if err := u2.Scan(src); err != nil {
+			t.Errorf("Error scanning OrderedBinaryUUID from %v: %s", src, err)
+		}
+		if !Equal(u.UUID, u2.UUID) {
+			t.Errorf("UUIDs should be equal: %s and %s", u, u2)
+		}
+	}
+}
+
+func TestOrderedBinaryUUIDSortsByTime(t *testing.T) {
+	g := NewGenerator(WithClock(fixedClock))
+	u1 := g.NewV1()
+	g = NewGenerator(WithClock(func() time.Time { return fixedClock().Add(time.Hour) }))
+	u2 := g.NewV1()
+
+	v1, _ := OrderedBinaryUUID{u1}.Value()
+	v2, _ := OrderedBinaryUUID{u2}.Value()
+	if bytes.Compare(v1.([]byte), v2.([]byte)) >= 0 {
+		t.Errorf("OrderedBinaryUUID values should sort by time: %x and %x", v1, v2)
+	}
+}
+
+func TestNullBinaryUUID(t *testing.T) {
+	val, err := NullBinaryUUID{}.Value()
+	if err != nil || val != nil {
+		t.Errorf("Invalid NullBinaryUUID value should be nil, got %v, %v", val, err)
+	}
+
+	val, _ = NullBinaryUUID{UUID: NamespaceDNS, Valid: true}.Value()
+	if b, ok := val.([]byte); !ok || !bytes.Equal(b, NamespaceDNS.Bytes()) {
+		t.Errorf("NullBinaryUUID value should be %x, got %v", NamespaceDNS.Bytes(), val)
+	}
+
+	u := NullBinaryUUID{}
+	if err := u.Scan(NamespaceDNS.Bytes()); err != nil || !u.Valid || !Equal(u.UUID, NamespaceDNS) {
+		t.Errorf("Error scanning NullBinaryUUID: %v, %v", u, err)
+	}
+	if err := u.Scan(nil); err != nil || u.Valid || !Equal(u.UUID, Nil) {
+		t.Errorf("Error scanning nil NullBinaryUUID: %v, %v", u, err)
+	}
+}
+
+func TestNullOrderedBinaryUUID(t *testing.T) {
+	val, err := NullOrderedBinaryUUID{}.Value()
+	if err != nil || val != nil {
+		t.Errorf("Invalid NullOrderedBinaryUUID value should be nil, got %v, %v", val, err)
+	}
+
+	src, _ := OrderedBinaryUUID{NamespaceDNS}.Value()
+	u := NullOrderedBinaryUUID{}
+	if err := u.Scan(src); err != nil || !u.Valid || !Equal(u.UUID, NamespaceDNS) {
+		t.Errorf("Error scanning NullOrderedBinaryUUID: %v, %v", u, err)
+	}
+	if err := u.Scan(nil); err != nil || u.Valid || !Equal(u.UUID, Nil) {
+		t.Errorf("Error scanning nil NullOrderedBinaryUUID: %v, %v", u, err)
+	}
+}
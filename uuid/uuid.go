@@ -3,16 +3,15 @@ package uuid
 import (
 	"bytes"
 	"crypto/md5"
-	"crypto/rand"
 	"crypto/sha1"
 	"database/sql/driver"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash"
 	"net"
 	"os"
-	"sync"
 	"time"
 )
 
@@ -24,6 +23,19 @@ const (
 	VariantFuture
 )
 
+// UUID versions.
+const (
+	_ = iota
+	V1
+	V2
+	V3
+	V4
+	V5
+	V6
+	V7
+	V8
+)
+
 // UUID DCE domains.
 const (
 	DomainPerson = iota
@@ -38,58 +50,48 @@ const epochStart = 122192928000000000
 // Used in string method conversion
 const dash byte = '-'
 
-// UUID v1/v2 storage.
+// Default clock and POSIX identity used for UUID generation.
 var (
-	storageMutex  sync.Mutex
-	storageOnce   sync.Once
-	epochFunc     = unixTimeFunc
-	clockSequence uint16
-	lastTime      uint64
-	hardwareAddr  [6]byte
-	posixUID      = uint32(os.Getuid())
-	posixGID      = uint32(os.Getgid())
+	epochFunc = unixTimeFunc
+	posixUID  = uint32(os.Getuid())
+	posixGID  = uint32(os.Getgid())
 )
 
-// String parse helpers.
+// Parse errors, wrapped in a *ParseError.
 var (
-	urnPrefix  = []byte("urn:uuid:")
-	byteGroups = []int{8, 4, 4, 4, 12}
+	ErrTooShort       = errors.New("uuid: UUID string too short")
+	ErrTooLong        = errors.New("uuid: UUID string too long")
+	ErrInvalidFormat  = errors.New("uuid: invalid UUID string format")
+	ErrInvalidVersion = errors.New("uuid: invalid UUID version")
+	ErrInvalidVariant = errors.New("uuid: invalid UUID variant")
 )
 
-func initClockSequence() {
-	buf := make([]byte, 2)
-	safeRandom(buf)
-	clockSequence = binary.BigEndian.Uint16(buf)
+// A ParseError occurs when a string cannot be parsed as a UUID.
+// Err is one of the parse error variables and can be tested with errors.Is.
+type ParseError struct {
+	Input string
+	Err   error
 }
 
-func initHardwareAddr() {
-	interfaces, err := net.Interfaces()
-	if err == nil {
-		for _, iface := range interfaces {
-			if len(iface.HardwareAddr) >= 6 {
-				copy(hardwareAddr[:], iface.HardwareAddr)
-				return
-			}
-		}
-	}
-
-	// Initialize hardwareAddr randomly in case
-	// of real network interfaces absence
-	safeRandom(hardwareAddr[:])
-
-	// Set multicast bit as recommended in RFC 4122
-	hardwareAddr[0] |= 0x01
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %q", e.Err, e.Input)
 }
 
-func initStorage() {
-	initClockSequence()
-	initHardwareAddr()
+// Unwrap returns the underlying parse error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
 }
 
-func safeRandom(dest []byte) {
-	if _, err := rand.Read(dest); err != nil {
-		panic(err)
-	}
+// String parse helpers.
+var (
+	urnPrefix  = []byte("urn:uuid:")
+	byteGroups = []int{8, 4, 4, 4, 12}
+)
+
+// Converts 100-nanosecond intervals since UUID epoch (October 15, 1582) to time.
+func timeFromEpoch(t uint64) time.Time {
+	ticks := int64(t) - epochStart
+	return time.Unix(ticks/1e7, (ticks%1e7)*100).UTC()
 }
 
 // Returns difference in 100-nanosecond intervals between
@@ -114,6 +116,10 @@ type NullUUID struct {
 // 128 bits set to zero.
 var Nil = UUID{}
 
+// The max UUID is special form of UUID that is specified to have all
+// 128 bits set to one.
+var Max = UUID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
 // Predefined namespace UUIDs.
 var (
 	NamespaceDNS, _  = FromString("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
@@ -145,6 +151,18 @@ func Equal(u1 UUID, u2 UUID) bool {
 	return bytes.Equal(u1[:], u2[:])
 }
 
+// Compare returns an integer comparing two UUIDs byte-wise.
+// The result will be 0 if u1 == u2, -1 if u1 < u2, and +1 if u1 > u2.
+func Compare(u1 UUID, u2 UUID) int {
+	return bytes.Compare(u1[:], u2[:])
+}
+
+// Less returns true if u1 sorts before u2 byte-wise.
+// Time-ordered V6 and V7 UUIDs sort by creation time.
+func Less(u1 UUID, u2 UUID) bool {
+	return Compare(u1, u2) < 0
+}
+
 // Version returns algorithm version used to generate UUID.
 func (u UUID) Version() uint {
 	return uint(u[6] >> 4)
@@ -168,6 +186,74 @@ func (u UUID) Bytes() []byte {
 	return u[:]
 }
 
+// Timestamp returns the time encoded in a V7 UUID with millisecond precision.
+// It will return error if the UUID is not a V7 UUID.
+func (u UUID) Timestamp() (time.Time, error) {
+	if u.Version() != 7 {
+		return time.Time{}, fmt.Errorf("%w: version %d has no Unix timestamp", ErrInvalidVersion, u.Version())
+	}
+	ms := uint64(u[0])<<40 | uint64(u[1])<<32 | uint64(binary.BigEndian.Uint32(u[2:]))
+
+	return time.UnixMilli(int64(ms)).UTC(), nil
+}
+
+// Time returns the time encoded in a time-based UUID.
+// V1 and V6 UUIDs have 100-nanosecond precision, V7 UUIDs have millisecond precision.
+// V2 UUIDs replace the low 32 bits of the timestamp with the POSIX UID/GID,
+// so their time is truncated to roughly 7 minutes.
+// It will return error for UUID versions that do not encode time.
+func (u UUID) Time() (time.Time, error) {
+	var t uint64
+
+	switch u.Version() {
+	case V1:
+		t = uint64(binary.BigEndian.Uint16(u[6:])&0x0fff)<<48 |
+			uint64(binary.BigEndian.Uint16(u[4:]))<<32 |
+			uint64(binary.BigEndian.Uint32(u[0:]))
+	case V2:
+		t = uint64(binary.BigEndian.Uint16(u[6:])&0x0fff)<<48 |
+			uint64(binary.BigEndian.Uint16(u[4:]))<<32
+	case V6:
+		t = uint64(binary.BigEndian.Uint32(u[0:]))<<28 |
+			uint64(binary.BigEndian.Uint16(u[4:]))<<12 |
+			uint64(binary.BigEndian.Uint16(u[6:])&0x0fff)
+	case V7:
+		return u.Timestamp()
+	default:
+		return time.Time{}, fmt.Errorf("%w: version %d is not time-based", ErrInvalidVersion, u.Version())
+	}
+
+	return timeFromEpoch(t), nil
+}
+
+// ClockSequence returns the clock sequence of a V1, V2 or V6 UUID.
+// V1 and V6 UUIDs carry 14 bits of clock sequence, V2 UUIDs only 6 bits
+// as the low byte holds the DCE domain.
+// It will return error for other UUID versions.
+func (u UUID) ClockSequence() (uint16, error) {
+	switch u.Version() {
+	case V1, V6:
+		return binary.BigEndian.Uint16(u[8:]) & 0x3fff, nil
+	case V2:
+		return uint16(u[8] & 0x3f), nil
+	}
+	return 0, fmt.Errorf("%w: version %d has no clock sequence", ErrInvalidVersion, u.Version())
+}
+
+// NodeID returns the node ID, usually the MAC address of the generating host,
+// of a V1, V2 or V6 UUID. A node ID with the multicast bit set was
+// generated randomly because no network interface was available.
+// It will return error for other UUID versions.
+func (u UUID) NodeID() (net.HardwareAddr, error) {
+	switch u.Version() {
+	case V1, V2, V6:
+		node := make(net.HardwareAddr, 6)
+		copy(node, u[10:])
+		return node, nil
+	}
+	return nil, fmt.Errorf("%w: version %d has no node ID", ErrInvalidVersion, u.Version())
+}
+
 // Returns canonical string representation of UUID:
 // xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
 func (u UUID) String() string {
@@ -207,55 +293,48 @@ func (u UUID) MarshalText() (text []byte, err error) {
 // Following formats are supported:
 // "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
 // "{6ba7b810-9dad-11d1-80b4-00c04fd430c8}",
-// "urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8"
-func (u *UUID) UnmarshalText(text []byte) (err error) {
-	if len(text) < 32 {
-		err = fmt.Errorf("uuid: UUID string too short: %s", text)
-		return
+// "urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+// "6ba7b8109dad11d180b400c04fd430c8"
+// The URN prefix is case-insensitive and surrounding whitespace is ignored.
+// Errors are of type *ParseError and wrap ErrTooShort, ErrTooLong or ErrInvalidFormat.
+func (u *UUID) UnmarshalText(text []byte) error {
+	t := bytes.TrimSpace(text)
+
+	if len(t) >= len(urnPrefix) && bytes.EqualFold(t[:len(urnPrefix)], urnPrefix) {
+		t = t[len(urnPrefix):]
+	} else if len(t) > 0 && t[0] == '{' {
+		if t[len(t)-1] != '}' {
+			return &ParseError{Input: string(text), Err: ErrInvalidFormat}
+		}
+		t = t[1 : len(t)-1]
 	}
 
-	t := text[:]
-	braced := false
-
-	if bytes.Equal(t[:9], urnPrefix) {
-		t = t[9:]
-	} else if t[0] == '{' {
-		braced = true
-		t = t[1:]
+	hyphenated := bytes.IndexByte(t, dash) >= 0
+	switch {
+	case hyphenated && len(t) < 36, !hyphenated && len(t) < 32:
+		return &ParseError{Input: string(text), Err: ErrTooShort}
+	case hyphenated && len(t) > 36, !hyphenated && len(t) > 32:
+		return &ParseError{Input: string(text), Err: ErrTooLong}
 	}
 
 	b := u[:]
-
 	for i, byteGroup := range byteGroups {
-		if i > 0 {
-			if t[0] != '-' {
-				err = fmt.Errorf("uuid: invalid string format")
-				return
+		if i > 0 && hyphenated {
+			if t[0] != dash {
+				return &ParseError{Input: string(text), Err: ErrInvalidFormat}
 			}
 			t = t[1:]
 		}
 
-		if len(t) < byteGroup {
-			err = fmt.Errorf("uuid: UUID string too short: %s", text)
-			return
-		}
-
-		if i == 4 && len(t) > byteGroup &&
-			((braced && t[byteGroup] != '}') || len(t[byteGroup:]) > 1 || !braced) {
-			err = fmt.Errorf("uuid: UUID string too long: %s", text)
-			return
-		}
-
-		_, err = hex.Decode(b[:byteGroup/2], t[:byteGroup])
-		if err != nil {
-			return
+		if _, err := hex.Decode(b[:byteGroup/2], t[:byteGroup]); err != nil {
+			return &ParseError{Input: string(text), Err: ErrInvalidFormat}
 		}
 
 		t = t[byteGroup:]
 		b = b[byteGroup/2:]
 	}
 
-	return
+	return nil
 }
 
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
@@ -344,6 +423,26 @@ func FromString(input string) (u UUID, err error) {
 	return
 }
 
+// ParseStrict returns UUID parsed from string input like FromString, but
+// also rejects UUIDs that do not use the RFC 4122/9562 variant (ErrInvalidVariant)
+// or a version defined by RFC 9562 (ErrInvalidVersion). The special Nil and Max
+// UUIDs are accepted.
+func ParseStrict(input string) (u UUID, err error) {
+	if u, err = FromString(input); err != nil {
+		return
+	}
+	if u == Nil || u == Max {
+		return
+	}
+	if u.Variant() != VariantRFC4122 {
+		return Nil, &ParseError{Input: input, Err: ErrInvalidVariant}
+	}
+	if v := u.Version(); v < V1 || v > V8 {
+		return Nil, &ParseError{Input: input, Err: ErrInvalidVersion}
+	}
+	return
+}
+
 // FromStringOrNil returns UUID parsed from string input.
 // Same behavior as FromString, but returns a Nil UUID on error.
 func FromStringOrNil(input string) UUID {
@@ -354,68 +453,14 @@ func FromStringOrNil(input string) UUID {
 	return uuid
 }
 
-// Returns UUID v1/v2 storage state.
-// Returns epoch timestamp, clock sequence, and hardware address.
-func getStorage() (uint64, uint16, []byte) {
-	storageOnce.Do(initStorage)
-
-	storageMutex.Lock()
-	defer storageMutex.Unlock()
-
-	timeNow := epochFunc()
-	// Clock changed backwards since last UUID generation.
-	// Should increase clock sequence.
-	if timeNow <= lastTime {
-		clockSequence++
-	}
-	lastTime = timeNow
-
-	return timeNow, clockSequence, hardwareAddr[:]
-}
-
 // NewV1 returns UUID based on current timestamp and MAC address.
 func NewV1() UUID {
-	u := UUID{}
-
-	timeNow, clockSeq, hardwareAddr := getStorage()
-
-	binary.BigEndian.PutUint32(u[0:], uint32(timeNow))
-	binary.BigEndian.PutUint16(u[4:], uint16(timeNow>>32))
-	binary.BigEndian.PutUint16(u[6:], uint16(timeNow>>48))
-	binary.BigEndian.PutUint16(u[8:], clockSeq)
-
-	copy(u[10:], hardwareAddr)
-
-	u.SetVersion(1)
-	u.SetVariant()
-
-	return u
+	return defaultGenerator.NewV1()
 }
 
 // NewV2 returns DCE Security UUID based on POSIX UID/GID.
 func NewV2(domain byte) UUID {
-	u := UUID{}
-
-	timeNow, clockSeq, hardwareAddr := getStorage()
-
-	switch domain {
-	case DomainPerson:
-		binary.BigEndian.PutUint32(u[0:], posixUID)
-	case DomainGroup:
-		binary.BigEndian.PutUint32(u[0:], posixGID)
-	}
-
-	binary.BigEndian.PutUint16(u[4:], uint16(timeNow>>32))
-	binary.BigEndian.PutUint16(u[6:], uint16(timeNow>>48))
-	binary.BigEndian.PutUint16(u[8:], clockSeq)
-	u[9] = domain
-
-	copy(u[10:], hardwareAddr)
-
-	u.SetVersion(2)
-	u.SetVariant()
-
-	return u
+	return defaultGenerator.NewV2(domain)
 }
 
 // NewV3 returns UUID based on MD5 hash of namespace UUID and name.
@@ -429,12 +474,19 @@ func NewV3(ns UUID, name string) UUID {
 
 // NewV4 returns random generated UUID.
 func NewV4() UUID {
-	u := UUID{}
-	safeRandom(u[:])
-	u.SetVersion(4)
-	u.SetVariant()
+	return defaultGenerator.NewV4()
+}
 
-	return u
+// NewV4Batch returns n random generated UUIDs, reading randomness
+// for many UUIDs at once instead of once per UUID.
+func NewV4Batch(n int) []UUID {
+	return defaultGenerator.NewV4Batch(n)
+}
+
+// Fill sets each element of uuids to a random generated UUID,
+// reading randomness for many UUIDs at once instead of once per UUID.
+func Fill(uuids []UUID) {
+	defaultGenerator.Fill(uuids)
 }
 
 // NewV5 returns UUID based on SHA-1 hash of namespace UUID and name.
@@ -455,3 +507,27 @@ func newFromHash(h hash.Hash, ns UUID, name string) UUID {
 
 	return u
 }
+
+// NewV6 returns UUID based on current timestamp and MAC address with
+// the timestamp fields reordered so UUIDs sort by creation time,
+// as described in RFC 9562.
+func NewV6() UUID {
+	return defaultGenerator.NewV6()
+}
+
+// NewV7 returns time-ordered UUID based on Unix timestamp in milliseconds
+// as described in RFC 9562. UUIDs generated by the same process sort in
+// generation order, including within the same millisecond.
+func NewV7() UUID {
+	return defaultGenerator.NewV7()
+}
+
+// NewV8 returns UUID with vendor-specific custom content as described
+// in RFC 9562. Only the version and variant bits of custom are overwritten.
+func NewV8(custom [16]byte) UUID {
+	u := UUID(custom)
+	u.SetVersion(8)
+	u.SetVariant()
+
+	return u
+}
@@ -2,7 +2,11 @@ package uuid
 
 import (
 	"bytes"
+	"errors"
+	"net"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestBytes(t *testing.T) {
@@ -235,7 +239,6 @@ func TestFromStringLong(t *testing.T) {
 func TestFromStringInvalid(t *testing.T) {
 	// Invalid UUID string formats
 	s := []string{
-		"6ba7b8109dad11d180b400c04fd430c8",
 		"6ba7b8109dad11d180b400c04fd430c86ba7b8109dad11d180b400c04fd430c8",
 		"urn:uuid:{6ba7b810-9dad-11d1-80b4-00c04fd430c8}",
 		"6ba7b8109-dad-11d1-80b4-00c04fd430c8",
@@ -610,3 +613,350 @@ func TestNewV5(t *testing.T) {
 		t.Errorf("UUIDv3 generated same UUIDs for sane names in different namespaces: %s and %s", u1, u4)
 	}
 }
+
+func TestNewV7(t *testing.T) {
+	u := NewV7()
+
+	if u.Version() != 7 {
+		t.Errorf("UUIDv7 generated with incorrect version: %d", u.Version())
+	}
+
+	if u.Variant() != VariantRFC4122 {
+		t.Errorf("UUIDv7 generated with incorrect variant: %d", u.Variant())
+	}
+
+	u1 := NewV7()
+	u2 := NewV7()
+
+	if Equal(u1, u2) {
+		t.Errorf("UUIDv7 generated two equal UUIDs: %s and %s", u1, u2)
+	}
+}
+
+func TestNewV7Monotonic(t *testing.T) {
+	now := time.UnixMilli(1234567890123)
+	g := NewGenerator(WithClock(func() time.Time { return now }))
+
+	prev := g.NewV7()
+	for i := 0; i < 10000; i++ {
+		u := g.NewV7()
+		if bytes.Compare(prev[:], u[:]) >= 0 {
+			t.Fatalf("UUIDv7 generated out of order: %s after %s", u, prev)
+		}
+		prev = u
+	}
+
+	// Clock moving backwards must not break ordering either.
+	now = now.Add(-time.Hour)
+	u := g.NewV7()
+	if bytes.Compare(prev[:], u[:]) >= 0 {
+		t.Errorf("UUIDv7 generated out of order after clock moved backwards: %s after %s", u, prev)
+	}
+}
+
+func TestNewV7Concurrent(t *testing.T) {
+	const workers, perWorker = 8, 1000
+
+	var mu sync.Mutex
+	seen := make(map[UUID]bool, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				u := NewV7()
+				mu.Lock()
+				seen[u] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != workers*perWorker {
+		t.Errorf("UUIDv7 generated %d duplicates", workers*perWorker-len(seen))
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	g := NewGenerator(WithClock(func() time.Time { return time.UnixMilli(1645557742000) }))
+
+	ts, err := g.NewV7().Timestamp()
+	if err != nil {
+		t.Fatalf("Error extracting UUIDv7 timestamp: %s", err)
+	}
+	if want := time.UnixMilli(1645557742000); !ts.Equal(want) {
+		t.Errorf("Incorrect UUIDv7 timestamp: %s, expected %s", ts, want)
+	}
+
+	if _, err := NewV4().Timestamp(); err == nil {
+		t.Errorf("Should return error extracting timestamp from UUIDv4")
+	}
+}
+
+func TestNewV6(t *testing.T) {
+	u := NewV6()
+
+	if u.Version() != 6 {
+		t.Errorf("UUIDv6 generated with incorrect version: %d", u.Version())
+	}
+
+	if u.Variant() != VariantRFC4122 {
+		t.Errorf("UUIDv6 generated with incorrect variant: %d", u.Variant())
+	}
+
+	u1 := NewV6()
+	u2 := NewV6()
+
+	if Equal(u1, u2) {
+		t.Errorf("UUIDv6 generated two equal UUIDs: %s and %s", u1, u2)
+	}
+
+	oldFunc := epochFunc
+	epochFunc = func() uint64 { return epochStart + 1645557742000*10000 }
+	defer func() { epochFunc = oldFunc }()
+
+	ts, err := NewV6().Time()
+	if err != nil {
+		t.Fatalf("Error extracting UUIDv6 time: %s", err)
+	}
+	if want := time.Unix(1645557742, 0); !ts.Equal(want) {
+		t.Errorf("Incorrect UUIDv6 time: %s, expected %s", ts, want)
+	}
+}
+
+func TestNewV8(t *testing.T) {
+	custom := [16]byte{0x32, 0x0c, 0x3d, 0x4d, 0xcc, 0x00, 0x0f, 0x75, 0x6d, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde}
+	u := NewV8(custom)
+
+	if u.Version() != 8 {
+		t.Errorf("UUIDv8 generated with incorrect version: %d", u.Version())
+	}
+
+	if u.Variant() != VariantRFC4122 {
+		t.Errorf("UUIDv8 generated with incorrect variant: %d", u.Variant())
+	}
+
+	if u.String() != "320c3d4d-cc00-8f75-ad12-3456789abcde" {
+		t.Errorf("UUIDv8 generated incorrectly: %s", u.String())
+	}
+}
+
+func TestTime(t *testing.T) {
+	// Test vectors from RFC 9562 Appendix A, all minted at
+	// Tuesday, February 22, 2022 2:22:22.00 PM GMT-05:00.
+	want := time.Date(2022, time.February, 22, 19, 22, 22, 0, time.UTC)
+
+	tests := []struct {
+		in      string
+		version uint
+	}{
+		{"c232ab00-9414-11ec-b3c8-9f6bdeced846", V1},
+		{"1ec9414c-232a-6b00-b3c8-9f6bdeced846", V6},
+		{"017f22e2-79b0-7cc3-98c4-dc0c0c07398f", V7},
+	}
+
+	for _, tt := range tests {
+		u, err := FromString(tt.in)
+		if err != nil {
+			t.Errorf("Error parsing UUID %s: %s", tt.in, err)
+			continue
+		}
+		if u.Version() != tt.version {
+			t.Errorf("Incorrect version for UUID %s: %d, expected %d", tt.in, u.Version(), tt.version)
+		}
+		ts, err := u.Time()
+		if err != nil {
+			t.Errorf("Error extracting time from UUID %s: %s", tt.in, err)
+			continue
+		}
+		if !ts.Equal(want) {
+			t.Errorf("Incorrect time for UUID %s: %s, expected %s", tt.in, ts, want)
+		}
+	}
+
+	if _, err := NewV4().Time(); err == nil {
+		t.Errorf("Should return error extracting time from UUIDv4")
+	}
+}
+
+func TestClockSequence(t *testing.T) {
+	u, _ := FromString("c232ab00-9414-11ec-b3c8-9f6bdeced846")
+	seq, err := u.ClockSequence()
+	if err != nil {
+		t.Fatalf("Error extracting clock sequence: %s", err)
+	}
+	if seq != 0x33c8 {
+		t.Errorf("Incorrect clock sequence: %#x, expected %#x", seq, 0x33c8)
+	}
+
+	u, _ = FromString("1ec9414c-232a-6b00-b3c8-9f6bdeced846")
+	if seq, _ := u.ClockSequence(); seq != 0x33c8 {
+		t.Errorf("Incorrect UUIDv6 clock sequence: %#x, expected %#x", seq, 0x33c8)
+	}
+
+	u2 := NewV2(DomainGroup)
+	if _, err := u2.ClockSequence(); err != nil {
+		t.Errorf("Error extracting UUIDv2 clock sequence: %s", err)
+	}
+
+	if _, err := NewV4().ClockSequence(); err == nil {
+		t.Errorf("Should return error extracting clock sequence from UUIDv4")
+	}
+}
+
+func TestNodeID(t *testing.T) {
+	u, _ := FromString("c232ab00-9414-11ec-b3c8-9f6bdeced846")
+	node, err := u.NodeID()
+	if err != nil {
+		t.Fatalf("Error extracting node ID: %s", err)
+	}
+	if node.String() != "9f:6b:de:ce:d8:46" {
+		t.Errorf("Incorrect node ID: %s", node)
+	}
+
+	defaultGenerator.storageOnce.Do(defaultGenerator.initStorage)
+	hardwareAddr := defaultGenerator.hardwareAddr
+	for _, u := range []UUID{NewV1(), NewV2(DomainPerson), NewV6()} {
+		node, err := u.NodeID()
+		if err != nil {
+			t.Errorf("Error extracting node ID from UUIDv%d: %s", u.Version(), err)
+			continue
+		}
+		if !bytes.Equal(node, hardwareAddr[:]) {
+			t.Errorf("Incorrect node ID for UUIDv%d: %s, expected %s", u.Version(), node, net.HardwareAddr(hardwareAddr[:]))
+		}
+	}
+
+	if _, err := NewV4().NodeID(); err == nil {
+		t.Errorf("Should return error extracting node ID from UUIDv4")
+	}
+}
+
+func TestTimeV1V2(t *testing.T) {
+	oldFunc := epochFunc
+	epochFunc = func() uint64 { return epochStart + 1645557742123456*10 }
+	defer func() { epochFunc = oldFunc }()
+
+	ts, err := NewV1().Time()
+	if err != nil {
+		t.Fatalf("Error extracting UUIDv1 time: %s", err)
+	}
+	if want := time.UnixMicro(1645557742123456); !ts.Equal(want) {
+		t.Errorf("Incorrect UUIDv1 time: %s, expected %s", ts, want)
+	}
+
+	ts, err = NewV2(DomainPerson).Time()
+	if err != nil {
+		t.Fatalf("Error extracting UUIDv2 time: %s", err)
+	}
+	if want := time.UnixMicro(1645557742123456); ts.After(want) || want.Sub(ts) > 430*time.Second {
+		t.Errorf("Incorrect UUIDv2 time: %s, expected about %s", ts, want)
+	}
+}
+
+func TestFromStringFlexible(t *testing.T) {
+	s := []string{
+		"6ba7b8109dad11d180b400c04fd430c8",
+		"6BA7B8109DAD11D180B400C04FD430C8",
+		"URN:UUID:6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"Urn:Uuid:6ba7b8109dad11d180b400c04fd430c8",
+		"{6ba7b8109dad11d180b400c04fd430c8}",
+		"  6ba7b810-9dad-11d1-80b4-00c04fd430c8\n",
+		"\t{6ba7b810-9dad-11d1-80b4-00c04fd430c8} ",
+	}
+
+	for _, str := range s {
+		u, err := FromString(str)
+		if err != nil {
+			t.Errorf("Error parsing UUID from string %q: %s", str, err)
+			continue
+		}
+		if !Equal(u, NamespaceDNS) {
+			t.Errorf("UUIDs should be equal: %s and %s", u, NamespaceDNS)
+		}
+	}
+}
+
+func TestFromStringErrors(t *testing.T) {
+	tests := []struct {
+		in  string
+		err error
+	}{
+		{"", ErrTooShort},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c", ErrTooShort},
+		{"6ba7b8109dad11d180b400c04fd430c", ErrTooShort},
+		{"urn:uuid:6ba7b810-9dad-11d1-80b4", ErrTooShort},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c8=", ErrTooLong},
+		{"6ba7b8109dad11d180b400c04fd430c800", ErrTooLong},
+		{"{6ba7b810-9dad-11d1-80b4-00c04fd430c8", ErrInvalidFormat},
+		{"6ba7b8109-dad-11d1-80b4-00c04fd430c8", ErrInvalidFormat},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430cx", ErrInvalidFormat},
+		{"6ba7b8109dad11d180b400c04fd430cx", ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		_, err := FromString(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Parsing %q should return %v, got %v", tt.in, tt.err, err)
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Input != tt.in {
+			t.Errorf("Parsing %q should return *ParseError with input, got %#v", tt.in, err)
+		}
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	valid := []string{
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"017f22e2-79b0-7cc3-98c4-dc0c0c07398f",
+		"00000000-0000-0000-0000-000000000000",
+		"ffffffff-ffff-ffff-ffff-ffffffffffff",
+	}
+
+	for _, str := range valid {
+		if _, err := ParseStrict(str); err != nil {
+			t.Errorf("Error parsing UUID strictly from string %s: %s", str, err)
+		}
+	}
+
+	tests := []struct {
+		in  string
+		err error
+	}{
+		{"6ba7b810-9dad-11d1-00b4-00c04fd430c8", ErrInvalidVariant},
+		{"6ba7b810-9dad-11d1-c0b4-00c04fd430c8", ErrInvalidVariant},
+		{"6ba7b810-9dad-01d1-80b4-00c04fd430c8", ErrInvalidVersion},
+		{"6ba7b810-9dad-f1d1-80b4-00c04fd430c8", ErrInvalidVersion},
+		{"6ba7b810-9dad", ErrTooShort},
+	}
+
+	for _, tt := range tests {
+		u, err := ParseStrict(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Strict parsing %q should return %v, got %v", tt.in, tt.err, err)
+		}
+		if u != Nil {
+			t.Errorf("Strict parsing %q should return Nil UUID on error, got %s", tt.in, u)
+		}
+	}
+}
+
+func TestVersionErrors(t *testing.T) {
+	u := NewV4()
+
+	if _, err := u.Time(); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("Time of UUIDv4 should return ErrInvalidVersion, got %v", err)
+	}
+	if _, err := u.Timestamp(); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("Timestamp of UUIDv4 should return ErrInvalidVersion, got %v", err)
+	}
+	if _, err := u.ClockSequence(); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("ClockSequence of UUIDv4 should return ErrInvalidVersion, got %v", err)
+	}
+	if _, err := u.NodeID(); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("NodeID of UUIDv4 should return ErrInvalidVersion, got %v", err)
+	}
+}